@@ -0,0 +1,51 @@
+// Package audit is a core audit package
+package audit
+
+import (
+	"github.com/sirupsen/logrus"
+)
+
+// Event security event name
+type Event string
+
+const (
+	// EventRefreshTokenReuse rotated refresh token was presented again,
+	// the token family was revoked
+	EventRefreshTokenReuse Event = "refresh_token_reuse"
+	// EventUserRoleChanged admin changed role of user
+	EventUserRoleChanged Event = "user_role_changed"
+	// EventUserDeactivated admin deactivated user, sessions of user were revoked
+	EventUserDeactivated Event = "user_deactivated"
+	// EventUserReactivated admin reactivated user
+	EventUserReactivated Event = "user_reactivated"
+	// EventPasswordResetForced admin forced user to reset password, sessions of user were revoked
+	EventPasswordResetForced Event = "password_reset_forced"
+	// EventImpersonationTokenIssued admin obtained token acting as user by token exchange
+	EventImpersonationTokenIssued Event = "impersonation_token_issued"
+	// EventPasswordReset user reset forgotten password, sessions of user were revoked
+	EventPasswordReset Event = "password_reset"
+	// EventPasswordChanged user changed password
+	EventPasswordChanged Event = "password_changed"
+	// EventTwoFactorEnabled user enabled two-factor authentication
+	EventTwoFactorEnabled Event = "two_factor_enabled"
+	// EventTwoFactorDisabled user disabled two-factor authentication
+	EventTwoFactorDisabled Event = "two_factor_disabled"
+	// EventRecoveryCodeUsed user signed in by recovery code
+	EventRecoveryCodeUsed Event = "recovery_code_used"
+	// EventPasskeyRegistered user registered passkey
+	EventPasskeyRegistered Event = "passkey_registered"
+	// EventPasskeyDeleted user deleted passkey
+	EventPasskeyDeleted Event = "passkey_deleted"
+	// EventPasskeyCloned sign count of passkey did not increase, passkey may be cloned
+	EventPasskeyCloned Event = "passkey_cloned"
+	// EventMagicLinkConfirmed user confirmed magic link opened on another device than it was requested on
+	EventMagicLinkConfirmed Event = "magic_link_confirmed"
+)
+
+// Security emit security event, events are written as structured logs
+// with the `security_event` field so they can be alerted on
+func Security(event Event, fields logrus.Fields) {
+	logrus.WithFields(fields).
+		WithField("security_event", event).
+		Warnf("security event: %s", event)
+}
@@ -20,6 +20,8 @@ import (
 var (
 	// CF -> for use configs model
 	CF = &Configs{}
+
+	reloadHooks []func(cf *Configs)
 )
 
 // DatabaseConfig database config model
@@ -42,6 +44,14 @@ type DatabaseConfig struct {
 type RedisConfig struct {
 }
 
+// SigningKeyConfig jwt signing key config
+type SigningKeyConfig struct {
+	ID            string `mapstructure:"ID"`
+	SigningMethod string `mapstructure:"SIGNING_METHOD"`
+	PrivateKey    string `mapstructure:"PRIVATE_KEY"`
+	PublicKey     string `mapstructure:"PUBLIC_KEY"`
+}
+
 // Configs config models
 type Configs struct {
 	UniversalTranslator *ut.UniversalTranslator
@@ -70,8 +80,10 @@ type Configs struct {
 		IdleTimeout  time.Duration `mapstructure:"IDLE_TIMEOUT"`
 	} `mapstructure:"HTTP_SERVER"`
 	User struct {
-		URL  string `mapstructure:"URL"`
-		Path struct {
+		URL      string `mapstructure:"URL"`
+		Audience string `mapstructure:"AUDIENCE"`
+		Scope    string `mapstructure:"SCOPE"`
+		Path     struct {
 			Profile string `mapstructure:"PROFILE"`
 		} `mapstructure:"PATH"`
 	} `mapstructure:"USER"`
@@ -91,10 +103,91 @@ type Configs struct {
 		Password string `mapstructure:"PASSWORD"`
 	} `mapstructure:"REDIS"`
 	JWT struct {
-		ExpireTime             time.Duration `mapstructure:"EXPIRE_TIME"`
-		Secret                 string        `mapstructure:"SECRET"`
-		RefreshTokenExpireTime time.Duration `mapstructure:"REFRESH_EXPIRATION_TIME"`
+		ExpireTime             time.Duration      `mapstructure:"EXPIRE_TIME"`
+		Secret                 string             `mapstructure:"SECRET"`
+		RefreshTokenExpireTime time.Duration      `mapstructure:"REFRESH_EXPIRATION_TIME"`
+		ServiceTokenExpireTime time.Duration      `mapstructure:"SERVICE_TOKEN_EXPIRATION_TIME"`
+		ImpersonateExpireTime  time.Duration      `mapstructure:"IMPERSONATION_TOKEN_EXPIRATION_TIME"`
+		TokenHashSecret        string             `mapstructure:"TOKEN_HASH_SECRET"`
+		LegacyTokenKeys        bool               `mapstructure:"LEGACY_TOKEN_KEYS"`
+		SigningMethod          string             `mapstructure:"SIGNING_METHOD"`
+		ActiveKeyID            string             `mapstructure:"ACTIVE_KEY_ID"`
+		Keys                   []SigningKeyConfig `mapstructure:"KEYS"`
 	} `mapstructure:"JWT"`
+	OAuth struct {
+		Issuer                      string        `mapstructure:"ISSUER"`
+		ConsentURL                  string        `mapstructure:"CONSENT_URL"`
+		AuthorizationCodeExpireTime time.Duration `mapstructure:"AUTHORIZATION_CODE_EXPIRE_TIME"`
+		DeviceVerificationURL       string        `mapstructure:"DEVICE_VERIFICATION_URL"`
+		DeviceCodeExpireTime        time.Duration `mapstructure:"DEVICE_CODE_EXPIRE_TIME"`
+		DeviceCodeInterval          time.Duration `mapstructure:"DEVICE_CODE_INTERVAL"`
+	} `mapstructure:"OAUTH"`
+	Mail struct {
+		Host     string `mapstructure:"HOST"`
+		Port     int    `mapstructure:"PORT"`
+		Username string `mapstructure:"USERNAME"`
+		Password string `mapstructure:"PASSWORD"`
+		From     string `mapstructure:"FROM"`
+		LogOnly  bool   `mapstructure:"LOG_ONLY"`
+	} `mapstructure:"MAIL"`
+	EmailVerification struct {
+		URL            string        `mapstructure:"URL"`
+		ExpireTime     time.Duration `mapstructure:"EXPIRE_TIME"`
+		ResendInterval time.Duration `mapstructure:"RESEND_INTERVAL"`
+		Required       bool          `mapstructure:"REQUIRED"`
+	} `mapstructure:"EMAIL_VERIFICATION"`
+	PasswordReset struct {
+		URL            string        `mapstructure:"URL"`
+		ExpireTime     time.Duration `mapstructure:"EXPIRE_TIME"`
+		ResendInterval time.Duration `mapstructure:"RESEND_INTERVAL"`
+	} `mapstructure:"PASSWORD_RESET"`
+	VerificationCode struct {
+		Length        int           `mapstructure:"LENGTH"`
+		MaxFailures   int64         `mapstructure:"MAX_FAILURES"`
+		LockoutTime   time.Duration `mapstructure:"LOCKOUT_TIME"`
+		IPMaxRequests int64         `mapstructure:"IP_MAX_REQUESTS"`
+		IPWindow      time.Duration `mapstructure:"IP_WINDOW"`
+	} `mapstructure:"VERIFICATION_CODE"`
+	SMS struct {
+		Provider string `mapstructure:"PROVIDER"`
+		File     string `mapstructure:"FILE"`
+	} `mapstructure:"SMS"`
+	PhoneLogin struct {
+		ExpireTime     time.Duration `mapstructure:"EXPIRE_TIME"`
+		ResendInterval time.Duration `mapstructure:"RESEND_INTERVAL"`
+	} `mapstructure:"PHONE_LOGIN"`
+	MagicLink struct {
+		URL            string        `mapstructure:"URL"`
+		ExpireTime     time.Duration `mapstructure:"EXPIRE_TIME"`
+		ResendInterval time.Duration `mapstructure:"RESEND_INTERVAL"`
+	} `mapstructure:"MAGIC_LINK"`
+	TwoFactor struct {
+		Issuer               string        `mapstructure:"ISSUER"`
+		EnrollmentExpireTime time.Duration `mapstructure:"ENROLLMENT_EXPIRE_TIME"`
+		ChallengeExpireTime  time.Duration `mapstructure:"CHALLENGE_EXPIRE_TIME"`
+		RecoveryCodeCount    int           `mapstructure:"RECOVERY_CODE_COUNT"`
+	} `mapstructure:"TWO_FACTOR"`
+	WebAuthn struct {
+		RPID               string        `mapstructure:"RP_ID"`
+		RPDisplayName      string        `mapstructure:"RP_DISPLAY_NAME"`
+		RPOrigins          []string      `mapstructure:"RP_ORIGINS"`
+		CeremonyExpireTime time.Duration `mapstructure:"CEREMONY_EXPIRE_TIME"`
+	} `mapstructure:"WEBAUTHN"`
+	PasswordPolicy struct {
+		HistorySize           int                      `mapstructure:"HISTORY_SIZE"`
+		MaxAge                time.Duration            `mapstructure:"MAX_AGE"`
+		RoleMaxAges           map[string]time.Duration `mapstructure:"ROLE_MAX_AGES"`
+		ChangeTokenExpireTime time.Duration            `mapstructure:"CHANGE_TOKEN_EXPIRE_TIME"`
+	} `mapstructure:"PASSWORD_POLICY"`
+	ForwardAuth struct {
+		CacheTime time.Duration `mapstructure:"CACHE_TIME"`
+	} `mapstructure:"FORWARD_AUTH"`
+	RBAC struct {
+		PermissionCacheTime time.Duration `mapstructure:"PERMISSION_CACHE_TIME"`
+	} `mapstructure:"RBAC"`
+	ServiceClient struct {
+		ClientID string `mapstructure:"CLIENT_ID"`
+	} `mapstructure:"SERVICE_CLIENT"`
 }
 
 // InitConfig init config
@@ -121,11 +214,20 @@ func InitConfig(configPath string, env string) error {
 			logrus.Error("binding error:", err)
 			return
 		}
+
+		for _, hook := range reloadHooks {
+			hook(CF)
+		}
 	})
 
 	return nil
 }
 
+// OnReload register hook to be called after the config file changed and binding again
+func OnReload(hook func(cf *Configs)) {
+	reloadHooks = append(reloadHooks, hook)
+}
+
 // bindingConfig binding config
 func bindingConfig(vp *viper.Viper, cf *Configs) error {
 	if err := vp.Unmarshal(&cf); err != nil {
@@ -131,6 +131,13 @@ type ReturnResult struct {
 	SuspenCall                   Result `mapstructure:"suspen_call"`
 	PleaseChangePassword         Result `mapstructure:"please_change_password"`
 	AlreadyUsedLastPassword      Result `mapstructure:"already_used_last_password"`
+	RoleAlreadyExists            Result `mapstructure:"role_already_exists"`
+	EmailNotVerified             Result `mapstructure:"email_not_verified"`
+	TwoFactorAlreadyEnabled      Result `mapstructure:"two_factor_already_enabled"`
+	TwoFactorNotEnabled          Result `mapstructure:"two_factor_not_enabled"`
+	InvalidPasskey               Result `mapstructure:"invalid_passkey"`
+	MagicLinkConfirmRequired     Result `mapstructure:"magic_link_confirmation_required"`
+	PhonePasswordRequired        Result `mapstructure:"phone_password_required"`
 	Internal                     struct {
 		Success          Result `mapstructure:"success" json:"success"`
 		General          Result `mapstructure:"general" json:"general"`
@@ -5,6 +5,8 @@ import (
 	"net/http"
 	"reflect"
 	"strconv"
+	"strings"
+	"time"
 
 	"ecommerce-authen/internal/core/config"
 	"ecommerce-authen/internal/core/sql"
@@ -124,7 +126,34 @@ func (c *Context) Validate(i interface{}) error {
 // Claims jwt claims
 type Claims struct {
 	jwt.StandardClaims
-	Role models.UserRole `json:"role"`
+	Role          models.UserRole `json:"role"`
+	SessionID     string          `json:"sid,omitempty"`
+	Scope         string          `json:"scope,omitempty"`
+	ClientID      string          `json:"client_id,omitempty"`
+	AuthMethods   []string        `json:"amr,omitempty"`
+	AuthTime      int64           `json:"auth_time,omitempty"`
+	Actor         *models.Actor   `json:"act,omitempty"`
+	EmailVerified *bool           `json:"email_verified,omitempty"`
+}
+
+// IsImpersonation token acts for user on behalf of an admin
+func (c *Claims) IsImpersonation() bool {
+	return c.Actor != nil
+}
+
+// Scopes granted scopes
+func (c *Claims) Scopes() []string {
+	return strings.Fields(c.Scope)
+}
+
+// HasScope token granted scope
+func (c *Claims) HasScope(scope string) bool {
+	return utils.ContainsString(c.Scopes(), scope)
+}
+
+// IsServiceToken token issued to client itself by client credentials grant
+func (c *Claims) IsServiceToken() bool {
+	return c.ClientID != "" && c.Subject == c.ClientID
 }
 
 // GetClaims get user claims
@@ -147,6 +176,26 @@ func (c *Context) GetUserID() uint {
 	return 0
 }
 
+// GetBearerToken get bearer token of authorization header
+func (c *Context) GetBearerToken() string {
+	strArr := strings.Split(c.Get(fiber.HeaderAuthorization), " ")
+	if len(strArr) != 2 {
+		return ""
+	}
+
+	return strArr[1]
+}
+
+// GetAccessToken get raw access token of current request
+func (c *Context) GetAccessToken() string {
+	token, ok := c.fiberCtx().Locals(UserKey).(*jwt.Token)
+	if ok {
+		return token.Raw
+	}
+
+	return ""
+}
+
 // GetRole get user claims find role
 func (c *Context) GetRole() models.UserRole {
 	token, ok := c.fiberCtx().Locals(UserKey).(*jwt.Token)
@@ -159,6 +208,109 @@ func (c *Context) GetRole() models.UserRole {
 	return models.UnknownRole
 }
 
+// claims claims of current request, nil when request is not authorized
+func (c *Context) claims() *Claims {
+	token, ok := c.fiberCtx().Locals(UserKey).(*jwt.Token)
+	if ok {
+		if cl, ok := token.Claims.(*Claims); ok {
+			return cl
+		}
+	}
+
+	return nil
+}
+
+// GetTokenID get token id (jti)
+func (c *Context) GetTokenID() string {
+	if cl := c.claims(); cl != nil {
+		return cl.Id
+	}
+
+	return ""
+}
+
+// GetIssuer get issuer of token (iss)
+func (c *Context) GetIssuer() string {
+	if cl := c.claims(); cl != nil {
+		return cl.Issuer
+	}
+
+	return ""
+}
+
+// GetAudience get audience of token (aud)
+func (c *Context) GetAudience() string {
+	if cl := c.claims(); cl != nil {
+		return cl.Audience
+	}
+
+	return ""
+}
+
+// GetSessionID get session id (sid)
+func (c *Context) GetSessionID() string {
+	if cl := c.claims(); cl != nil {
+		return cl.SessionID
+	}
+
+	return ""
+}
+
+// GetClientID get oauth client id of token
+func (c *Context) GetClientID() string {
+	if cl := c.claims(); cl != nil {
+		return cl.ClientID
+	}
+
+	return ""
+}
+
+// GetScopes get granted scopes
+func (c *Context) GetScopes() []string {
+	if cl := c.claims(); cl != nil {
+		return cl.Scopes()
+	}
+
+	return nil
+}
+
+// HasScope token granted scope
+func (c *Context) HasScope(scope string) bool {
+	if cl := c.claims(); cl != nil {
+		return cl.HasScope(scope)
+	}
+
+	return false
+}
+
+// GetActorID get id of admin impersonating user, 0 when token is not an impersonation
+func (c *Context) GetActorID() uint {
+	if cl := c.claims(); cl != nil && cl.Actor != nil {
+		id, _ := strconv.Atoi(cl.Actor.Subject)
+		return uint(id)
+	}
+
+	return 0
+}
+
+// GetAuthMethods get authentication methods (amr)
+func (c *Context) GetAuthMethods() []string {
+	if cl := c.claims(); cl != nil {
+		return cl.AuthMethods
+	}
+
+	return nil
+}
+
+// GetAuthTime get time user authenticated
+func (c *Context) GetAuthTime() time.Time {
+	if cl := c.claims(); cl != nil && cl.AuthTime > 0 {
+		return time.Unix(cl.AuthTime, 0)
+	}
+
+	return time.Time{}
+}
+
 // GetDatabase get connection database `postgresql`
 func (c *Context) GetDatabase() *gorm.DB {
 	val := c.Locals(PostgreDatabaseKey)
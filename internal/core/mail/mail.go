@@ -0,0 +1,69 @@
+// Package mail is a core mail package
+package mail
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"errors"
+
+	"github.com/sirupsen/logrus"
+	"gopkg.in/gomail.v2"
+)
+
+// Sender mail sender interface
+type Sender interface {
+	Send(to, subject, body string) error
+}
+
+// Init check mail config, mails are only logged when LOG_ONLY is on
+// which writes codes and links in plain text so it is refused in release
+func Init(cf *config.Configs) error {
+	if cf.Mail.LogOnly {
+		if cf.App.Release {
+			return errors.New("mail LOG_ONLY is for development only")
+		}
+
+		return nil
+	}
+
+	if cf.Mail.Host == "" {
+		return errors.New("mail HOST is required, set LOG_ONLY for development")
+	}
+
+	return nil
+}
+
+// New new mail sender, call Init at startup to refuse a missing smtp host
+func New() Sender {
+	cf := config.CF.Mail
+	if cf.LogOnly {
+		return &logSender{}
+	}
+
+	return &smtpSender{
+		from:   cf.From,
+		dialer: gomail.NewDialer(cf.Host, cf.Port, cf.Username, cf.Password),
+	}
+}
+
+type smtpSender struct {
+	from   string
+	dialer *gomail.Dialer
+}
+
+// Send send html mail
+func (s *smtpSender) Send(to, subject, body string) error {
+	m := gomail.NewMessage()
+	m.SetHeader("From", s.from)
+	m.SetHeader("To", to)
+	m.SetHeader("Subject", subject)
+	m.SetBody("text/html", body)
+	return s.dialer.DialAndSend(m)
+}
+
+type logSender struct{}
+
+// Send log mail for local development
+func (s *logSender) Send(to, subject, body string) error {
+	logrus.Infof("[mail] to=%s subject=%s body=%s", to, subject, body)
+	return nil
+}
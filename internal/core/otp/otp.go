@@ -2,6 +2,11 @@
 package otp
 
 import (
+	"bytes"
+	"crypto/rand"
+	"fmt"
+	"image/png"
+	"math/big"
 	"time"
 
 	"github.com/pquerna/otp"
@@ -10,10 +15,25 @@ import (
 
 const period = (60 * time.Minute)
 
+const (
+	// authenticatorQRCodeSize width and height of qr code of authenticator key in pixels
+	authenticatorQRCodeSize = 256
+)
+
 // Interface otp interface
 type Interface interface {
 	GenerateCode() (string, string, error)
+	GenerateRandomCode(length int) (string, error)
 	ValidateCode(code, secret string) bool
+	GenerateAuthenticatorKey(issuer, account string) (*AuthenticatorKey, error)
+	ValidateAuthenticatorCode(code, secret string) bool
+}
+
+// AuthenticatorKey key of authenticator app (6 digits, 30 seconds)
+type AuthenticatorKey struct {
+	Secret string
+	URI    string
+	QRCode []byte
 }
 
 // New otp
@@ -46,6 +66,18 @@ func (o OTP) GenerateCode() (string, string, error) {
 	return code, key.Secret(), err
 }
 
+// GenerateRandomCode generate random numeric code of length digits,
+// codes sent to users must be random as they are only valid while stored
+func (o OTP) GenerateRandomCode(length int) (string, error) {
+	max := new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(length)), nil)
+	n, err := rand.Int(rand.Reader, max)
+	if err != nil {
+		return "", err
+	}
+
+	return fmt.Sprintf("%0*d", length, n), nil
+}
+
 // ValidateCode validate code
 func (o OTP) ValidateCode(code, secret string) bool {
 	second := uint(period.Seconds())
@@ -63,3 +95,35 @@ func (o OTP) ValidateCode(code, secret string) bool {
 
 	return success
 }
+
+// GenerateAuthenticatorKey generate key of authenticator app with otpauth uri and png qr code of uri
+func (o OTP) GenerateAuthenticatorKey(issuer, account string) (*AuthenticatorKey, error) {
+	key, err := totp.Generate(totp.GenerateOpts{
+		Issuer:      issuer,
+		AccountName: account,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	img, err := key.Image(authenticatorQRCodeSize, authenticatorQRCodeSize)
+	if err != nil {
+		return nil, err
+	}
+
+	buf := &bytes.Buffer{}
+	if err := png.Encode(buf, img); err != nil {
+		return nil, err
+	}
+
+	return &AuthenticatorKey{
+		Secret: key.Secret(),
+		URI:    key.URL(),
+		QRCode: buf.Bytes(),
+	}, nil
+}
+
+// ValidateAuthenticatorCode validate code of authenticator app, codes of one period before and after are valid
+func (o OTP) ValidateAuthenticatorCode(code, secret string) bool {
+	return totp.Validate(code, secret)
+}
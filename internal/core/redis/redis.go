@@ -26,9 +26,18 @@ type Client interface {
 	Ping() error
 	Get(key string, value interface{}) error
 	GetKeys(pattern string) ([]string, error)
+	GetDelete(key string, value interface{}) error
 	Set(key string, value interface{}, expiredTime time.Duration) error
+	SetNX(key string, value interface{}, expiredTime time.Duration) (bool, error)
+	Exists(key string) (bool, error)
 	GetExpire(key string) (int64, error)
+	GetTTL(key string) (time.Duration, error)
 	Delete(key string) error
+	SetAdd(key string, member string, expiredTime time.Duration) error
+	SetMembers(key string) ([]string, error)
+	SetRemove(key string, member string) error
+	Increment(key string, expiredTime time.Duration) (int64, error)
+	GetCounter(key string) (int64, error)
 	Close()
 	MapRedisKey(r *http.Request, data interface{}, prefixKey string) string
 }
@@ -103,6 +112,25 @@ func (cache *client) GetExpire(key string) (int64, error) {
 	return ttl, nil
 }
 
+// GetTTL get remaining time to live of key, zero when key has no expire
+func (cache *client) GetTTL(key string) (time.Duration, error) {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	ttl, err := redis.Int64(conn.Do("TTL", key))
+	if err != nil {
+		return 0, err
+	}
+
+	if ttl < 0 {
+		return 0, nil
+	}
+
+	return time.Duration(ttl) * time.Second, nil
+}
+
 // Get get value from key
 func (cache *client) Get(key string, value interface{}) error {
 	conn := cache.pool.Get()
@@ -125,6 +153,42 @@ func (cache *client) Get(key string, value interface{}) error {
 	return nil
 }
 
+// GetDelete get value from key and delete key in one transaction,
+// so only one of concurrent callers gets the value
+func (cache *client) GetDelete(key string, value interface{}) error {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	if err := conn.Send("MULTI"); err != nil {
+		return err
+	}
+
+	if err := conn.Send("GET", key); err != nil {
+		return err
+	}
+
+	if err := conn.Send("DEL", key); err != nil {
+		return err
+	}
+
+	replies, err := redis.Values(conn.Do("EXEC"))
+	if err != nil {
+		return err
+	}
+
+	str, err := redis.String(replies[0], nil)
+	if err != nil {
+		return err
+	}
+
+	b := bytes.Buffer{}
+	b.Write([]byte(str))
+	d := gob.NewDecoder(&b)
+	return d.Decode(value)
+}
+
 // GetKeys get keys
 func (cache *client) GetKeys(pattern string) ([]string, error) {
 	conn := cache.pool.Get()
@@ -181,6 +245,47 @@ func (cache *client) Set(key string, value interface{}, expiredTime time.Duratio
 	return err
 }
 
+// SetNX set value to key only when key does not exist, in one command
+// so only one of concurrent callers gets true
+func (cache *client) SetNX(key string, value interface{}, expiredTime time.Duration) (bool, error) {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	b := bytes.Buffer{}
+	e := gob.NewEncoder(&b)
+	if err := e.Encode(value); err != nil {
+		return false, err
+	}
+
+	args := redis.Args{key, b.Bytes(), "NX"}
+	if expiredTime.Seconds() > 1 {
+		args = args.Add("EX", int64(expiredTime.Seconds()))
+	}
+
+	_, err := redis.String(conn.Do("SET", args...))
+	if err == redis.ErrNil {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// Exists key exists
+func (cache *client) Exists(key string) (bool, error) {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	return redis.Bool(conn.Do("EXISTS", key))
+}
+
 // Delete delete key
 func (cache *client) Delete(key string) error {
 	conn := cache.pool.Get()
@@ -192,6 +297,86 @@ func (cache *client) Delete(key string) error {
 	return err
 }
 
+// SetAdd add member to set and extend expire of set
+func (cache *client) SetAdd(key string, member string, expiredTime time.Duration) error {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	_, err := conn.Do("SADD", key, member)
+	if err != nil {
+		return err
+	}
+
+	if expiredTime.Seconds() > 1 {
+		_, err = conn.Do("EXPIRE", key, expiredTime.Seconds())
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// SetMembers get all members of set
+func (cache *client) SetMembers(key string) ([]string, error) {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	return redis.Strings(conn.Do("SMEMBERS", key))
+}
+
+// SetRemove remove member from set
+func (cache *client) SetRemove(key string, member string) error {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	_, err := conn.Do("SREM", key, member)
+	return err
+}
+
+// Increment increment counter of key, expire is only set by first increment
+// so the counter is reset once the window has passed
+func (cache *client) Increment(key string, expiredTime time.Duration) (int64, error) {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	count, err := redis.Int64(conn.Do("INCR", key))
+	if err != nil {
+		return 0, err
+	}
+
+	if count == 1 && expiredTime.Seconds() > 1 {
+		if _, err := conn.Do("EXPIRE", key, expiredTime.Seconds()); err != nil {
+			return 0, err
+		}
+	}
+
+	return count, nil
+}
+
+// GetCounter get counter of key, zero when key does not exist
+func (cache *client) GetCounter(key string) (int64, error) {
+	conn := cache.pool.Get()
+	defer func() {
+		_ = conn.Close()
+	}()
+
+	count, err := redis.Int64(conn.Do("GET", key))
+	if err == redis.ErrNil {
+		return 0, nil
+	}
+
+	return count, err
+}
+
 // Close close pool redis
 func (cache *client) Close() {
 	_ = cache.pool.Close()
@@ -0,0 +1,95 @@
+// Package sms is a core sms package
+package sms
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// ProviderLog messages are only logged
+	ProviderLog = "log"
+	// ProviderFile messages are appended to file
+	ProviderFile = "file"
+)
+
+// SMSProvider sms gateway interface, implement it for the gateway in use
+type SMSProvider interface {
+	Send(phoneNumber, message string) error
+}
+
+// Init check provider of config, log and file providers write login codes
+// in plain text so they are refused in release
+func Init(cf *config.Configs) error {
+	switch cf.SMS.Provider {
+	case ProviderLog, ProviderFile:
+		if cf.App.Release {
+			return fmt.Errorf("sms provider=%s is for development only", cf.SMS.Provider)
+		}
+
+		return nil
+	}
+
+	return fmt.Errorf("unknown sms provider=%q", cf.SMS.Provider)
+}
+
+// New new sms provider by config, call Init at startup to refuse a missing provider
+func New() SMSProvider {
+	cf := config.CF.SMS
+	switch cf.Provider {
+	case ProviderFile:
+		return &fileProvider{path: cf.File}
+	case ProviderLog:
+		return &logProvider{}
+	}
+
+	return &unknownProvider{name: cf.Provider}
+}
+
+// unknownProvider provider of config that does not exist, messages are never sent
+type unknownProvider struct {
+	name string
+}
+
+// Send refuse message
+func (p *unknownProvider) Send(phoneNumber, message string) error {
+	return fmt.Errorf("unknown sms provider=%q", p.name)
+}
+
+type logProvider struct{}
+
+// Send log message for local development
+func (p *logProvider) Send(phoneNumber, message string) error {
+	logrus.Infof("[sms] to=%s message=%s", phoneNumber, message)
+	return nil
+}
+
+// fileProvider append messages to file, one line per message,
+// so tests can read codes without a gateway
+type fileProvider struct {
+	path  string
+	mutex sync.Mutex
+}
+
+// Send append message to file
+func (p *fileProvider) Send(phoneNumber, message string) error {
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	f, err := os.OpenFile(p.path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0600)
+	if err != nil {
+		return err
+	}
+
+	defer func() {
+		_ = f.Close()
+	}()
+
+	_, err = fmt.Fprintf(f, "%s\t%s\t%s\n", time.Now().Format(time.RFC3339), phoneNumber, message)
+	return err
+}
@@ -6,45 +6,85 @@ import (
 	"crypto/cipher"
 	"crypto/ecdsa"
 	"crypto/rand"
+	"crypto/rsa"
 	"ecommerce-authen/internal/core/config"
+	"encoding/base64"
 	"encoding/hex"
 	"fmt"
 	"io"
 	"os"
 
-	"github.com/dgrijalva/jwt-go"
+	"github.com/golang-jwt/jwt/v4"
 )
 
-var (
-	// SignKey is private key
-	SignKey = &ecdsa.PrivateKey{}
-	// VerifyKey is public key
-	VerifyKey = &ecdsa.PublicKey{}
-)
-
-// ReadECDSAKey read private key && public key
-func ReadECDSAKey(privateKey, publicKey string) error {
+// ReadECDSAKey read private key && public key,
+// the public key is derived from the private key when publicKey is empty
+func ReadECDSAKey(privateKey, publicKey string) (*ecdsa.PrivateKey, *ecdsa.PublicKey, error) {
 	privateKeyByte, err := os.ReadFile(privateKey)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	signKey, err := jwt.ParseECPrivateKeyFromPEM(privateKeyByte)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if publicKey == "" {
+		return signKey, &signKey.PublicKey, nil
 	}
 
 	publicKeyByte, err := os.ReadFile(publicKey)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	verifyKey, err := jwt.ParseECPublicKeyFromPEM(publicKeyByte)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signKey, verifyKey, nil
+}
+
+// ReadRSAKey read private key && public key,
+// the public key is derived from the private key when publicKey is empty
+func ReadRSAKey(privateKey, publicKey string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	privateKeyByte, err := os.ReadFile(privateKey)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	SignKey, err = jwt.ParseECPrivateKeyFromPEM(privateKeyByte)
+	signKey, err := jwt.ParseRSAPrivateKeyFromPEM(privateKeyByte)
 	if err != nil {
-		return err
+		return nil, nil, err
 	}
 
-	VerifyKey, err = jwt.ParseECPublicKeyFromPEM(publicKeyByte)
+	if publicKey == "" {
+		return signKey, &signKey.PublicKey, nil
+	}
+
+	publicKeyByte, err := os.ReadFile(publicKey)
 	if err != nil {
-		return err
+		return nil, nil, err
+	}
+
+	verifyKey, err := jwt.ParseRSAPublicKeyFromPEM(publicKeyByte)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return signKey, verifyKey, nil
+}
+
+// RandomToken url safe random token from n bytes of crypto/rand
+func RandomToken(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := io.ReadFull(rand.Reader, b); err != nil {
+		return "", err
 	}
 
-	return nil
+	return base64.RawURLEncoding.EncodeToString(b), nil
 }
 
 // Encrypt encrypt
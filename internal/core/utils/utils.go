@@ -113,6 +113,17 @@ func IntersectionString(a, b []string) []string {
 	return r
 }
 
+// ContainsString string array contains string
+func ContainsString(a []string, s string) bool {
+	for _, i := range a {
+		if i == s {
+			return true
+		}
+	}
+
+	return false
+}
+
 // FindDuplicateFromSlice find duplicate item from slice
 func FindDuplicateFromSlice(a []int64, b uint) bool {
 	for _, object := range a {
@@ -4,19 +4,28 @@ package middlewares
 import (
 	"ecommerce-authen/internal/core/config"
 	"ecommerce-authen/internal/core/context"
-	"ecommerce-authen/internal/core/redis"
 	"ecommerce-authen/internal/models"
-	"strings"
+	"ecommerce-authen/internal/pkg/rbac"
+	"ecommerce-authen/internal/pkg/token"
 
 	"github.com/gofiber/fiber/v2"
 )
 
-// AuthAsAdmin authorize as admin
-func AuthAsAdmin() fiber.Handler {
+// RequirePermission authorized user has permission by any of their roles,
+// tokens of oauth clients also need the permission granted as scope
+// and impersonation tokens never have permissions
+func RequirePermission(permission string) fiber.Handler {
+	rbacService := rbac.NewService()
 	return func(c *fiber.Ctx) error {
 		ctx := context.WithContext(c)
-		currentRole := ctx.GetRole()
-		if currentRole != models.RoleCustomer {
+		if ctx.GetActorID() != 0 || (ctx.GetClientID() != "" && !ctx.HasScope(permission)) {
+			return c.
+				Status(config.RR.InvalidPermissionRole.HTTPStatusCode()).
+				JSON(config.RR.InvalidPermissionRole.WithLocale(c))
+		}
+
+		permissions, err := rbacService.UserPermissions(ctx, ctx.GetUserID())
+		if err != nil || !models.HasPermission(permissions, permission) {
 			return c.
 				Status(config.RR.InvalidPermissionRole.HTTPStatusCode()).
 				JSON(config.RR.InvalidPermissionRole.WithLocale(c))
@@ -30,23 +39,22 @@ func AuthAsAdmin() fiber.Handler {
 func Authorize() fiber.Handler {
 	return func(c *fiber.Ctx) error {
 		ctx := context.WithContext(c)
-		conn := redis.GetConnection()
-		bearToken := ctx.Get("Authorization")
-		strArr := strings.Split(bearToken, " ")
-		var userID uint
-		err := conn.Get(strArr[1], &userID)
-		if err != nil {
+		accessToken := ctx.GetBearerToken()
+		if accessToken == "" {
 			return c.
 				Status(config.RR.InvalidToken.HTTPStatusCode()).
 				JSON(config.RR.InvalidToken.WithLocale(c))
 		}
 
-		if userID != ctx.GetUserID() {
+		t, err := token.VerifyAccessToken(accessToken, ctx.GetSource())
+		if err != nil {
+			result := err.(config.Result)
 			return c.
-				Status(config.RR.InvalidPermissionRole.HTTPStatusCode()).
-				JSON(config.RR.InvalidPermissionRole.WithLocale(c))
+				Status(result.HTTPStatusCode()).
+				JSON(result.WithLocale(c))
 		}
 
+		c.Locals(context.UserKey, t)
 		return c.Next()
 	}
 }
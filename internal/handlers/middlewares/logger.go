@@ -60,7 +60,7 @@ func Logger() fiber.Handler {
 		parameters := c.Locals(context.ParametersKey)
 		if parameters != nil {
 			b, _ := json.Marshal(parameters)
-			for _, f := range []string{"password"} {
+			for _, f := range []string{"password", "token", "refresh_token", "client_secret", "code", "code_verifier", "device_code"} {
 				if res := gjson.GetBytes(b, f); res.Exists() {
 					b, _ = sjson.SetBytes(b, f, "**********")
 				}
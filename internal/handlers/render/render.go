@@ -3,6 +3,7 @@ package render
 
 import (
 	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/models"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -19,8 +20,19 @@ func Download(c *fiber.Ctx, path, fileName string) error {
 	return c.Download(path, fileName)
 }
 
+// Redirect redirect client to url
+func Redirect(c *fiber.Ctx, url string) error {
+	return c.Redirect(url, fiber.StatusFound)
+}
+
 // Error render error to client
 func Error(c *fiber.Ctx, err error) error {
+	if oauthErr, ok := err.(models.OAuthError); ok {
+		return c.
+			Status(oauthErr.HTTPStatusCode()).
+			JSON(oauthErr)
+	}
+
 	errMsg := config.RR.Internal.ConnectionError
 	if locErr, ok := err.(config.Result); ok {
 		errMsg = locErr
@@ -143,3 +143,25 @@ func ResponseCustomSuccess(c *fiber.Ctx, fn interface{}, request interface{}) er
 
 	return render.JSON(c, out[0].Interface())
 }
+
+// ResponseRedirect handle response redirect
+func ResponseRedirect(c *fiber.Ctx, fn interface{}, request interface{}) error {
+	ctx := context.WithContext(c)
+	err := ctx.BindValue(request, true)
+	if err != nil {
+		logrus.Errorf("bind value error: %s", err)
+		return render.Error(c, err)
+	}
+
+	out := reflect.ValueOf(fn).Call([]reflect.Value{
+		reflect.ValueOf(ctx),
+		reflect.ValueOf(request),
+	})
+	errObj := out[1].Interface()
+	if errObj != nil {
+		logrus.Errorf("call service error: %s", errObj)
+		return render.Error(c, errObj.(error))
+	}
+
+	return render.Redirect(c, out[0].String())
+}
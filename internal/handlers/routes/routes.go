@@ -5,8 +5,18 @@ import (
 	ctx "context"
 	"ecommerce-authen/internal/core/config"
 	"ecommerce-authen/internal/handlers/middlewares"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/forwardauth"
 	"ecommerce-authen/internal/pkg/guest"
 	"ecommerce-authen/internal/pkg/healthcheck"
+	"ecommerce-authen/internal/pkg/oauth"
+	"ecommerce-authen/internal/pkg/passkey"
+	"ecommerce-authen/internal/pkg/password"
+	"ecommerce-authen/internal/pkg/rbac"
+	"ecommerce-authen/internal/pkg/session"
+	"ecommerce-authen/internal/pkg/twofactor"
+	"ecommerce-authen/internal/pkg/user"
+	"ecommerce-authen/internal/pkg/wellknown"
 	"fmt"
 	"os"
 	"os/signal"
@@ -69,15 +79,101 @@ func NewRouter() {
 	})
 
 	app.Get("/metrics", monitor.New())
+	wellKnownEndpoint := wellknown.NewEndpoint()
+	wellKnown := app.Group("/.well-known")
+	wellKnown.Get("/jwks.json", wellKnownEndpoint.JWKS)
+	wellKnown.Get("/openid-configuration", wellKnownEndpoint.OpenIDConfiguration)
+
 	healthzEndpoint := healthcheck.NewEndpoint()
 	healthz := v1.Group("healthz")
 	healthz.Get("/", healthzEndpoint.HealthCheck)
 
 	guestEndpoint := guest.NewEndpoint()
+	passwordEndpoint := password.NewEndpoint()
+	twoFactorEndpoint := twofactor.NewEndpoint()
+	passkeyEndpoint := passkey.NewEndpoint()
 	guest := v1.Group("g")
 	guest.Post("/register", guestEndpoint.Register)
 	guest.Post("/login", guestEndpoint.Login)
 	guest.Post("/token", guestEndpoint.RenewToken)
+	guest.Post("/email/verify", guestEndpoint.VerifyEmail)
+	guest.Post("/email/resend", guestEndpoint.ResendEmailVerification)
+	guest.Post("/password/forgot", guestEndpoint.ForgotPassword)
+	guest.Post("/password/reset", guestEndpoint.ResetPassword)
+	guest.Post("/password/change", passwordEndpoint.ChangeExpiredPassword)
+	guest.Post("/phone/code", guestEndpoint.RequestPhoneCode)
+	guest.Post("/phone/verify", guestEndpoint.VerifyPhoneCode)
+	guest.Post("/magic-link", guestEndpoint.RequestMagicLink)
+	guest.Post("/magic-link/verify", guestEndpoint.VerifyMagicLink)
+	guest.Post("/2fa/verify", twoFactorEndpoint.Verify)
+	guest.Post("/2fa/passkey/begin", twoFactorEndpoint.BeginPasskey)
+	guest.Post("/2fa/passkey/finish", twoFactorEndpoint.VerifyPasskey)
+	guest.Post("/passkey/login/begin", guestEndpoint.BeginPasskeyLogin)
+	guest.Post("/passkey/login/finish", guestEndpoint.FinishPasskeyLogin)
+
+	forwardAuthEndpoint := forwardauth.NewEndpoint()
+	v1.Get("/auth/verify", forwardAuthEndpoint.Verify)
+
+	sessionEndpoint := session.NewEndpoint()
+	v1.Post("/logout", middlewares.Authorize(), sessionEndpoint.Logout)
+
+	me := v1.Group("me", middlewares.Authorize())
+	me.Get("/sessions", sessionEndpoint.GetMySessions)
+	me.Post("/sessions/revoke-others", sessionEndpoint.RevokeMyOtherSessions)
+	me.Delete("/sessions/:id", sessionEndpoint.RevokeMySession)
+	me.Post("/password", passwordEndpoint.ChangePassword)
+	me.Post("/2fa/enroll", twoFactorEndpoint.Enroll)
+	me.Post("/2fa/confirm", twoFactorEndpoint.Confirm)
+	me.Post("/2fa/disable", twoFactorEndpoint.Disable)
+	me.Post("/2fa/recovery-codes", twoFactorEndpoint.RegenerateRecoveryCodes)
+	me.Get("/passkeys", passkeyEndpoint.GetMyPasskeys)
+	me.Post("/passkeys/register/begin", passkeyEndpoint.BeginRegistration)
+	me.Post("/passkeys/register/finish", passkeyEndpoint.FinishRegistration)
+	me.Put("/passkeys/:id", passkeyEndpoint.RenamePasskey)
+	me.Delete("/passkeys/:id", passkeyEndpoint.DeletePasskey)
+
+	oauthEndpoint := oauth.NewEndpoint()
+	rbacEndpoint := rbac.NewEndpoint()
+	usersRead := middlewares.RequirePermission(models.PermissionUsersRead)
+	usersWrite := middlewares.RequirePermission(models.PermissionUsersWrite)
+	rolesRead := middlewares.RequirePermission(models.PermissionRolesRead)
+	rolesWrite := middlewares.RequirePermission(models.PermissionRolesWrite)
+	clientsRead := middlewares.RequirePermission(models.PermissionClientsRead)
+	clientsWrite := middlewares.RequirePermission(models.PermissionClientsWrite)
+	userEndpoint := user.NewEndpoint()
+	admin := v1.Group("admin", middlewares.Authorize())
+	admin.Get("/users", usersRead, userEndpoint.GetUsers)
+	admin.Get("/users/:id", usersRead, userEndpoint.GetUser)
+	admin.Put("/users/:id/role", usersWrite, userEndpoint.ChangeRole)
+	admin.Post("/users/:id/deactivate", usersWrite, userEndpoint.Deactivate)
+	admin.Post("/users/:id/reactivate", usersWrite, userEndpoint.Reactivate)
+	admin.Post("/users/:id/password-reset", usersWrite, userEndpoint.ForcePasswordReset)
+	admin.Get("/users/:id/sessions", usersRead, sessionEndpoint.GetUserSessions)
+	admin.Delete("/users/:id/sessions", usersWrite, sessionEndpoint.RevokeUserSessions)
+	admin.Delete("/users/:id/sessions/:session_id", usersWrite, sessionEndpoint.RevokeUserSession)
+	admin.Get("/users/:id/roles", rolesRead, rbacEndpoint.GetUserRoles)
+	admin.Put("/users/:id/roles", rolesWrite, rbacEndpoint.UpdateUserRoles)
+	admin.Get("/permissions", rolesRead, rbacEndpoint.GetPermissions)
+	admin.Get("/roles", rolesRead, rbacEndpoint.GetRoles)
+	admin.Post("/roles", rolesWrite, rbacEndpoint.CreateRole)
+	admin.Put("/roles/:id", rolesWrite, rbacEndpoint.UpdateRole)
+	admin.Delete("/roles/:id", rolesWrite, rbacEndpoint.DeleteRole)
+	admin.Get("/oauth/clients", clientsRead, oauthEndpoint.GetClients)
+	admin.Post("/oauth/clients", clientsWrite, oauthEndpoint.CreateClient)
+	admin.Put("/oauth/clients/:id", clientsWrite, oauthEndpoint.UpdateClient)
+	admin.Post("/oauth/clients/:id/secret", clientsWrite, oauthEndpoint.RegenerateClientSecret)
+
+	oauth := v1.Group("oauth")
+	oauth.Get("/authorize", oauthEndpoint.Authorize)
+	oauth.Post("/authorize", middlewares.Authorize(), oauthEndpoint.ApproveAuthorize)
+	oauth.Post("/token", oauthEndpoint.Token)
+	oauth.Post("/device_authorization", oauthEndpoint.DeviceAuthorization)
+	oauth.Get("/device", middlewares.Authorize(), oauthEndpoint.GetDeviceVerification)
+	oauth.Post("/device", middlewares.Authorize(), oauthEndpoint.VerifyDevice)
+	oauth.Get("/userinfo", middlewares.Authorize(), oauthEndpoint.UserInfo)
+	oauth.Post("/userinfo", middlewares.Authorize(), oauthEndpoint.UserInfo)
+	oauth.Post("/revoke", oauthEndpoint.Revoke)
+	oauth.Post("/introspect", oauthEndpoint.Introspect)
 
 	c := make(chan os.Signal, 1)
 	signal.Notify(c, os.Interrupt)
@@ -0,0 +1,23 @@
+package models
+
+// Client oauth client
+type Client struct {
+	Model
+	ClientID           string      `json:"client_id"`
+	ClientSecretHash   string      `json:"-"`
+	Name               string      `json:"name"`
+	RedirectURIs       StringArray `json:"redirect_uris" gorm:"type:text[]"`
+	RedirectSchemes    StringArray `json:"redirect_schemes" gorm:"type:text[]"`
+	GrantTypes         StringArray `json:"grant_types" gorm:"type:text[]"`
+	Scopes             StringArray `json:"scopes" gorm:"type:text[]"`
+	Audiences          StringArray `json:"audiences" gorm:"type:text[]"`
+	Public             bool        `json:"public"`
+	FirstParty         bool        `json:"first_party"`
+	AllowIntrospection bool        `json:"allow_introspection"`
+	IsActive           bool        `json:"is_active"`
+}
+
+// TableName override table name
+func (Client) TableName() string {
+	return "oauth_clients"
+}
@@ -0,0 +1,13 @@
+package models
+
+// Identity identity of verified access token, forwarded to upstream services by the gateway
+type Identity struct {
+	UserID    uint     `json:"user_id"`
+	Role      UserRole `json:"role"`
+	SessionID string   `json:"session_id,omitempty"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	ActorID   uint     `json:"actor_id,omitempty"`
+	Audience  string   `json:"-"`
+	ExpiresAt int64    `json:"-"`
+}
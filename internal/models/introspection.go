@@ -0,0 +1,18 @@
+package models
+
+// Introspection token introspection response (RFC 7662)
+type Introspection struct {
+	Active    bool     `json:"active"`
+	Scope     string   `json:"scope,omitempty"`
+	ClientID  string   `json:"client_id,omitempty"`
+	TokenType string   `json:"token_type,omitempty"`
+	Exp       int64    `json:"exp,omitempty"`
+	Iat       int64    `json:"iat,omitempty"`
+	Sub       string   `json:"sub,omitempty"`
+	Aud       string   `json:"aud,omitempty"`
+	Iss       string   `json:"iss,omitempty"`
+	Jti       string   `json:"jti,omitempty"`
+	Role      UserRole `json:"role,omitempty"`
+	SessionID string   `json:"sid,omitempty"`
+	Act       *Actor   `json:"act,omitempty"`
+}
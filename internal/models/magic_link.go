@@ -0,0 +1,8 @@
+package models
+
+// MagicLink magic link request response
+type MagicLink struct {
+	// DeviceToken only issued when link is bound to the requesting device,
+	// send it with token of the link to sign in without confirmation
+	DeviceToken string `json:"device_token,omitempty"`
+}
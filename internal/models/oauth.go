@@ -0,0 +1,122 @@
+package models
+
+import (
+	"net/http"
+)
+
+const (
+	// GrantTypeAuthorizationCode grant type authorization code
+	GrantTypeAuthorizationCode = "authorization_code"
+	// GrantTypeRefreshToken grant type refresh token
+	GrantTypeRefreshToken = "refresh_token"
+	// GrantTypeClientCredentials grant type client credentials
+	GrantTypeClientCredentials = "client_credentials"
+	// GrantTypeDeviceCode grant type device code (RFC 8628)
+	GrantTypeDeviceCode = "urn:ietf:params:oauth:grant-type:device_code"
+	// GrantTypeTokenExchange grant type token exchange (RFC 8693)
+	GrantTypeTokenExchange = "urn:ietf:params:oauth:grant-type:token-exchange"
+
+	// TokenTypeAccessToken token type identifier of access token (RFC 8693 section 3)
+	TokenTypeAccessToken = "urn:ietf:params:oauth:token-type:access_token"
+
+	// ResponseTypeCode response type code
+	ResponseTypeCode = "code"
+	// CodeChallengeMethodS256 pkce code challenge method (RFC 7636)
+	CodeChallengeMethodS256 = "S256"
+
+	// TokenTypeBearer token type bearer
+	TokenTypeBearer = "Bearer"
+)
+
+const (
+	// OAuthErrorInvalidRequest invalid request
+	OAuthErrorInvalidRequest = "invalid_request"
+	// OAuthErrorInvalidClient invalid client
+	OAuthErrorInvalidClient = "invalid_client"
+	// OAuthErrorInvalidGrant invalid grant
+	OAuthErrorInvalidGrant = "invalid_grant"
+	// OAuthErrorUnauthorizedClient unauthorized client
+	OAuthErrorUnauthorizedClient = "unauthorized_client"
+	// OAuthErrorUnsupportedGrantType unsupported grant type
+	OAuthErrorUnsupportedGrantType = "unsupported_grant_type"
+	// OAuthErrorUnsupportedResponseType unsupported response type
+	OAuthErrorUnsupportedResponseType = "unsupported_response_type"
+	// OAuthErrorInvalidScope invalid scope
+	OAuthErrorInvalidScope = "invalid_scope"
+	// OAuthErrorAccessDenied access denied
+	OAuthErrorAccessDenied = "access_denied"
+	// OAuthErrorInvalidTarget invalid target (RFC 8707)
+	OAuthErrorInvalidTarget = "invalid_target"
+	// OAuthErrorAuthorizationPending device authorization is pending (RFC 8628)
+	OAuthErrorAuthorizationPending = "authorization_pending"
+	// OAuthErrorSlowDown device is polling too fast (RFC 8628)
+	OAuthErrorSlowDown = "slow_down"
+	// OAuthErrorExpiredToken device code expired (RFC 8628)
+	OAuthErrorExpiredToken = "expired_token"
+	// OAuthErrorServerError server error
+	OAuthErrorServerError = "server_error"
+)
+
+// OAuthError oauth error response (RFC 6749 section 5.2)
+type OAuthError struct {
+	Status      int    `json:"-"`
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+}
+
+// NewOAuthError new oauth error, invalid_client responds 401 and the others 400
+func NewOAuthError(code, description string) OAuthError {
+	status := http.StatusBadRequest
+	if code == OAuthErrorInvalidClient {
+		status = http.StatusUnauthorized
+	}
+
+	return OAuthError{
+		Status:      status,
+		Code:        code,
+		Description: description,
+	}
+}
+
+// Error error description
+func (e OAuthError) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+
+	return e.Code + ": " + e.Description
+}
+
+// HTTPStatusCode http status code
+func (e OAuthError) HTTPStatusCode() int {
+	return e.Status
+}
+
+// AuthorizationResponse authorization response, client is redirected to redirect uri
+type AuthorizationResponse struct {
+	RedirectURI string `json:"redirect_uri"`
+}
+
+// ClientWithSecret client with plain client secret, only returned once
+type ClientWithSecret struct {
+	*Client
+	ClientSecret string `json:"client_secret,omitempty"`
+}
+
+// DeviceAuthorization device authorization response (RFC 8628 section 3.2)
+type DeviceAuthorization struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int64  `json:"expires_in"`
+	Interval                int64  `json:"interval"`
+}
+
+// DeviceVerification device authorization waiting for user approval
+type DeviceVerification struct {
+	UserCode   string `json:"user_code"`
+	ClientID   string `json:"client_id"`
+	ClientName string `json:"client_name"`
+	Scope      string `json:"scope,omitempty"`
+}
@@ -0,0 +1,9 @@
+package models
+
+// Page page of items
+type Page struct {
+	Page  int         `json:"page"`
+	Size  int         `json:"size"`
+	Total int64       `json:"total"`
+	Items interface{} `json:"items"`
+}
@@ -0,0 +1,33 @@
+package models
+
+import "time"
+
+// Passkey webauthn credential of user
+type Passkey struct {
+	ID              uint        `json:"id" gorm:"primary_key"`
+	UserID          uint        `json:"-" gorm:"index"`
+	CredentialID    []byte      `json:"-" gorm:"uniqueIndex"`
+	PublicKey       []byte      `json:"-"`
+	AttestationType string      `json:"-"`
+	AAGUID          []byte      `json:"-"`
+	SignCount       uint32      `json:"-"`
+	Transports      StringArray `json:"transports" gorm:"type:text[]"`
+	BackupEligible  bool        `json:"backup_eligible"`
+	BackupState     bool        `json:"backup_state"`
+	Nickname        string      `json:"nickname"`
+	LastUsedAt      *time.Time  `json:"last_used_at,omitempty"`
+	CreatedAt       time.Time   `json:"created_at"`
+	UpdatedAt       time.Time   `json:"updated_at"`
+}
+
+// TableName override table name
+func (Passkey) TableName() string {
+	return "passkeys"
+}
+
+// PasskeyOptions options of webauthn ceremony for navigator.credentials,
+// the token is sent back with the credential when the ceremony has no signed in user
+type PasskeyOptions struct {
+	Token   string      `json:"token,omitempty"`
+	Options interface{} `json:"options"`
+}
@@ -0,0 +1,16 @@
+package models
+
+import "time"
+
+// PasswordHistory password hash previously set by user
+type PasswordHistory struct {
+	ID        uint      `json:"id" gorm:"primary_key"`
+	UserID    uint      `json:"user_id" gorm:"index"`
+	Password  string    `json:"-"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName override table name
+func (PasswordHistory) TableName() string {
+	return "password_histories"
+}
@@ -0,0 +1,131 @@
+package models
+
+import (
+	"strings"
+	"time"
+)
+
+const (
+	// PermissionAll every permission
+	PermissionAll = "*"
+	// PermissionUsersRead view users and their sessions
+	PermissionUsersRead = "users:read"
+	// PermissionUsersWrite manage users and revoke their sessions
+	PermissionUsersWrite = "users:write"
+	// PermissionUsersImpersonate obtain tokens acting as user by token exchange
+	PermissionUsersImpersonate = "users:impersonate"
+	// PermissionRolesRead view roles
+	PermissionRolesRead = "roles:read"
+	// PermissionRolesWrite manage roles and assign them to users
+	PermissionRolesWrite = "roles:write"
+	// PermissionClientsRead view oauth clients
+	PermissionClientsRead = "clients:read"
+	// PermissionClientsWrite manage oauth clients
+	PermissionClientsWrite = "clients:write"
+)
+
+// Permissions permissions known by authen service,
+// roles may hold permissions of other services too
+var Permissions = []string{
+	PermissionUsersRead,
+	PermissionUsersWrite,
+	PermissionUsersImpersonate,
+	PermissionRolesRead,
+	PermissionRolesWrite,
+	PermissionClientsRead,
+	PermissionClientsWrite,
+}
+
+// privilegedPermissions permissions that let a user act on other users, roles or clients
+var privilegedPermissions = []string{
+	PermissionUsersWrite,
+	PermissionUsersImpersonate,
+	PermissionRolesWrite,
+	PermissionClientsWrite,
+}
+
+// Name name of seeded role of user role
+func (r UserRole) Name() string {
+	switch r {
+	case RoleCustomer:
+		return "customer"
+	case RoleSeller:
+		return "seller"
+	case RoleAdmin:
+		return "admin"
+	}
+
+	return ""
+}
+
+// Role role with permissions
+type Role struct {
+	Model
+	Name        string      `json:"name" gorm:"uniqueIndex"`
+	Description string      `json:"description"`
+	Permissions StringArray `json:"permissions" gorm:"type:text[]"`
+	System      bool        `json:"system"`
+}
+
+// TableName override table name
+func (Role) TableName() string {
+	return "roles"
+}
+
+// UserRoleAssignment role assigned to user in addition to user role
+type UserRoleAssignment struct {
+	UserID    uint      `json:"user_id" gorm:"primaryKey"`
+	RoleID    uint      `json:"role_id" gorm:"primaryKey"`
+	CreatedAt time.Time `json:"created_at"`
+}
+
+// TableName override table name
+func (UserRoleAssignment) TableName() string {
+	return "user_roles"
+}
+
+// DefaultRoles roles seeded from user roles, they can be edited but not deleted
+func DefaultRoles() []Role {
+	return []Role{
+		{Name: RoleCustomer.Name(), Description: "Customer", Permissions: StringArray{}, System: true},
+		{Name: RoleSeller.Name(), Description: "Seller", Permissions: StringArray{}, System: true},
+		{Name: RoleAdmin.Name(), Description: "Administrator", Permissions: StringArray{PermissionAll}, System: true},
+	}
+}
+
+// HasPermission permissions grant permission,
+// `*` grants every permission and `users:*` every permission of users
+func HasPermission(permissions []string, permission string) bool {
+	resource := strings.SplitN(permission, ":", 2)[0]
+	for _, p := range permissions {
+		if p == permission || p == PermissionAll || p == resource+":*" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// GrantsAll permissions grant every one of granted permissions,
+// a wildcard is only granted by the same or a wider wildcard
+func GrantsAll(permissions, granted []string) bool {
+	for _, permission := range granted {
+		if !HasPermission(permissions, permission) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// HasPrivilegedPermission permissions grant managing users, roles or clients or impersonation,
+// users holding them can not be impersonated
+func HasPrivilegedPermission(permissions []string) bool {
+	for _, permission := range privilegedPermissions {
+		if HasPermission(permissions, permission) {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,75 @@
+package models
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHasPermission(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		permission  string
+		want        bool
+	}{
+		{name: "exact", permissions: []string{PermissionUsersRead}, permission: PermissionUsersRead, want: true},
+		{name: "other permission", permissions: []string{PermissionUsersRead}, permission: PermissionUsersWrite, want: false},
+		{name: "resource wildcard", permissions: []string{"users:*"}, permission: PermissionUsersWrite, want: true},
+		{name: "other resource wildcard", permissions: []string{"roles:*"}, permission: PermissionUsersRead, want: false},
+		{name: "all", permissions: []string{PermissionAll}, permission: PermissionClientsWrite, want: true},
+		{name: "prefix is not wildcard", permissions: []string{"users"}, permission: PermissionUsersRead, want: false},
+		{name: "none", permissions: nil, permission: PermissionUsersRead, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HasPermission(tt.permissions, tt.permission))
+		})
+	}
+}
+
+func TestHasPrivilegedPermission(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		want        bool
+	}{
+		{name: "customer", permissions: []string{}, want: false},
+		{name: "read only staff", permissions: []string{PermissionUsersRead, PermissionRolesRead}, want: false},
+		{name: "impersonation", permissions: []string{PermissionUsersImpersonate}, want: true},
+		{name: "users wildcard", permissions: []string{"users:*"}, want: true},
+		{name: "clients write", permissions: []string{PermissionClientsWrite}, want: true},
+		{name: "all", permissions: []string{PermissionAll}, want: true},
+		{name: "permission of other service", permissions: []string{"orders:write"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, HasPrivilegedPermission(tt.permissions))
+		})
+	}
+}
+
+func TestGrantsAll(t *testing.T) {
+	tests := []struct {
+		name        string
+		permissions []string
+		granted     []string
+		want        bool
+	}{
+		{name: "nothing granted", permissions: []string{}, granted: []string{}, want: true},
+		{name: "subset", permissions: []string{PermissionUsersRead, PermissionUsersWrite}, granted: []string{PermissionUsersRead}, want: true},
+		{name: "more than held", permissions: []string{PermissionUsersWrite}, granted: []string{PermissionUsersWrite, PermissionRolesWrite}, want: false},
+		{name: "covered by wildcard", permissions: []string{"users:*"}, granted: []string{PermissionUsersWrite, PermissionUsersImpersonate}, want: true},
+		{name: "wildcard needs wildcard", permissions: []string{PermissionUsersRead, PermissionUsersWrite}, granted: []string{"users:*"}, want: false},
+		{name: "all needs all", permissions: []string{"users:*", "roles:*", "clients:*"}, granted: []string{PermissionAll}, want: false},
+		{name: "all grants all", permissions: []string{PermissionAll}, granted: []string{PermissionAll, "orders:write"}, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, GrantsAll(tt.permissions, tt.granted))
+		})
+	}
+}
@@ -0,0 +1,20 @@
+package models
+
+import (
+	"time"
+)
+
+// Session login session, each session is one refresh token family
+type Session struct {
+	ID          string    `json:"id"`
+	UserID      uint      `json:"user_id"`
+	UserAgent   string    `json:"user_agent"`
+	IP          string    `json:"ip"`
+	LoginType   LoginType `json:"login_type"`
+	ClientID    string    `json:"client_id,omitempty"`
+	AuthMethods []string  `json:"amr,omitempty"`
+	ActorID     uint      `json:"actor_id,omitempty"`
+	CreatedAt   time.Time `json:"created_at"`
+	LastUsedAt  time.Time `json:"last_used_at"`
+	Current     bool      `json:"current"`
+}
@@ -4,11 +4,35 @@ import (
 	"time"
 )
 
+const (
+	// TokenTypeHintAccessToken token type hint access token (RFC 7009)
+	TokenTypeHintAccessToken = "access_token"
+	// TokenTypeHintRefreshToken token type hint refresh token (RFC 7009)
+	TokenTypeHintRefreshToken = "refresh_token"
+)
+
 // RefreshToken model
 type RefreshToken struct {
 	UserID       uint       `json:"-"`
+	SessionID    string     `json:"-"`
 	Role         UserRole   `json:"role,omitempty"`
 	JWTToken     string     `json:"token,omitempty"`
 	RefreshToken string     `json:"refresh_token,omitempty"`
 	ExpiredAt    *time.Time `json:"-"`
+	AccessToken  string     `json:"access_token,omitempty"`
+	TokenType    string     `json:"token_type,omitempty"`
+	ExpiresIn    int64      `json:"expires_in,omitempty"`
+	Scope        string     `json:"scope,omitempty"`
+	IDToken      string     `json:"id_token,omitempty"`
+
+	IssuedTokenType string `json:"issued_token_type,omitempty"`
+	// ChangePasswordToken only issued instead of tokens when password has expired
+	ChangePasswordToken string `json:"change_password_token,omitempty"`
+	// TwoFactorToken only issued instead of tokens when second step is required
+	TwoFactorToken string `json:"two_factor_token,omitempty"`
+}
+
+// Actor party acting for the subject of token (RFC 8693 section 4.1)
+type Actor struct {
+	Subject string `json:"sub"`
 }
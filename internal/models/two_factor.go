@@ -0,0 +1,31 @@
+package models
+
+import "time"
+
+// RecoveryCode one-time code to sign in when authenticator app is lost, only its hash is kept
+type RecoveryCode struct {
+	ID        uint       `json:"id" gorm:"primary_key"`
+	UserID    uint       `json:"user_id" gorm:"index"`
+	CodeHash  string     `json:"-"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	CreatedAt time.Time  `json:"created_at"`
+}
+
+// TableName override table name
+func (RecoveryCode) TableName() string {
+	return "recovery_codes"
+}
+
+// TwoFactorEnrollment authenticator key to add to authenticator app
+type TwoFactorEnrollment struct {
+	Secret     string `json:"secret"`
+	OTPAuthURI string `json:"otpauth_uri"`
+	// QRCode png qr code of otpauth uri as data uri
+	QRCode    string    `json:"qr_code"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// RecoveryCodes recovery codes, only shown once
+type RecoveryCodes struct {
+	Codes []string `json:"recovery_codes"`
+}
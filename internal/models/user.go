@@ -2,6 +2,8 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
 )
 
 // LoginType login channel
@@ -16,8 +18,34 @@ const (
 	LoginTypeGoogle
 	// LoginTypeFacebook login channel
 	LoginTypeFacebook
+	// LoginTypePhone login by code sent to phone number
+	LoginTypePhone
+	// LoginTypePasskey login by webauthn credential
+	LoginTypePasskey
+	// LoginTypeMagicLink login by link sent to email
+	LoginTypeMagicLink
 )
 
+// AuthMethods authentication methods (amr) of login type
+func (t LoginType) AuthMethods() []string {
+	switch t {
+	case LoginTypeNormal:
+		return []string{"pwd"}
+	case LoginTypeGoogle:
+		return []string{"google"}
+	case LoginTypeFacebook:
+		return []string{"facebook"}
+	case LoginTypePhone:
+		return []string{"sms"}
+	case LoginTypePasskey:
+		return []string{"hwk"}
+	case LoginTypeMagicLink:
+		return []string{"email"}
+	}
+
+	return nil
+}
+
 // UserRole user role
 type UserRole uint
 
@@ -44,9 +72,38 @@ type User struct {
 	FacebookID   string     `json:"facebook_id"`
 	AcceptPolicy bool       `json:"accept_policy"`
 	LastOnlineAt *time.Time `json:"last_online_at,omitempty"`
+
+	EmailVerifiedAt       *time.Time `json:"email_verified_at,omitempty"`
+	PasswordChangedAt     *time.Time `json:"password_changed_at,omitempty"`
+	PhoneVerifiedAt       *time.Time `json:"phone_verified_at,omitempty"`
+	TwoFactorSecret       string     `json:"-"`
+	TwoFactorEnabledAt    *time.Time `json:"two_factor_enabled_at,omitempty"`
+	DeactivatedAt         *time.Time `json:"deactivated_at,omitempty"`
+	PasswordResetRequired bool       `json:"password_reset_required"`
 }
 
 // TableName override table name
 func (User) TableName() string {
 	return "users"
 }
+
+// BeforeSave keep is_active in sync with deactivated_at, which decides whether user can sign in
+func (u *User) BeforeSave(_ *gorm.DB) error {
+	u.IsActive = !u.Deactivated()
+	return nil
+}
+
+// Deactivated user was deactivated by admin and can not sign in
+func (u *User) Deactivated() bool {
+	return u.DeactivatedAt != nil
+}
+
+// EmailVerified email is verified, emails from google sign in are verified by google
+func (u *User) EmailVerified() bool {
+	return u.EmailVerifiedAt != nil || u.GoogleID != ""
+}
+
+// TwoFactorEnabled user signs in with code of authenticator app as second step
+func (u *User) TwoFactorEnabled() bool {
+	return u.TwoFactorEnabledAt != nil
+}
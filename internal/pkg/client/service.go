@@ -2,7 +2,10 @@ package client
 
 import (
 	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
 	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/repositories"
 
 	"github.com/imroc/req"
 	"github.com/sirupsen/logrus"
@@ -15,18 +18,25 @@ type Service interface {
 	PutRequest(url string, header interface{}, param interface{}, body interface{}, v interface{}) error
 	PatchRequest(url string, header interface{}, param interface{}, body interface{}, v interface{}) error
 	DeleteRequest(url string, header interface{}, param interface{}, v interface{}) error
+	ServiceToken(c *context.Context, audience, scope string) (string, error)
+	GetRequestAsService(c *context.Context, audience, scope, url string, header req.Header, param interface{}, v interface{}) error
+	PostRequestAsService(c *context.Context, audience, scope, url string, header req.Header, param interface{}, body interface{}, v interface{}) error
 }
 
 type service struct {
-	config *config.Configs
-	result *config.ReturnResult
+	config           *config.Configs
+	result           *config.ReturnResult
+	tokenService     token.Service
+	clientRepository repositories.ClientRepository
 }
 
 // NewService new service
 func NewService() Service {
 	return &service{
-		config: config.CF,
-		result: config.RR,
+		config:           config.CF,
+		result:           config.RR,
+		tokenService:     token.NewService(),
+		clientRepository: repositories.ClientNewRepository(),
 	}
 }
 
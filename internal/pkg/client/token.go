@@ -0,0 +1,151 @@
+package client
+
+import (
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/imroc/req"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// serviceTokenLeeway renew cached service token before it expires
+	serviceTokenLeeway = 30 * time.Second
+)
+
+var (
+	tokens = &tokenCache{items: map[string]*cachedToken{}}
+
+	errServiceClientNotConfigured = errors.New("service client is not configured, set SERVICE_CLIENT.CLIENT_ID")
+)
+
+// cachedToken service token with its expiry
+type cachedToken struct {
+	accessToken string
+	expiresAt   time.Time
+}
+
+// tokenCache service tokens by audience and scope, shared by every service instance
+type tokenCache struct {
+	mutex sync.Mutex
+	items map[string]*cachedToken
+}
+
+// ServiceToken service token of audience issued to the configured service client
+// by the token service itself, the token is cached until shortly before it expires.
+// Concurrent callers missing the cache may each issue a token, the lock is never held while issuing.
+func (s *service) ServiceToken(c *context.Context, audience, scope string) (string, error) {
+	key := audience + " " + scope
+	tokens.mutex.Lock()
+	t, ok := tokens.items[key]
+	tokens.mutex.Unlock()
+	if ok && time.Now().Before(t.expiresAt) {
+		return t.accessToken, nil
+	}
+
+	t, err := s.issueServiceToken(c, audience, scope)
+	if err != nil {
+		return "", err
+	}
+
+	tokens.mutex.Lock()
+	tokens.items[key] = t
+	tokens.mutex.Unlock()
+	return t.accessToken, nil
+}
+
+// GetRequestAsService get request with service token of audience
+func (s *service) GetRequestAsService(c *context.Context, audience, scope, url string, header req.Header, param interface{}, v interface{}) error {
+	return s.requestAsService(c, audience, scope, header, func(h req.Header) error {
+		return s.GetRequest(url, h, param, v)
+	})
+}
+
+// PostRequestAsService post request with service token of audience
+func (s *service) PostRequestAsService(c *context.Context, audience, scope, url string, header req.Header, param interface{}, body interface{}, v interface{}) error {
+	return s.requestAsService(c, audience, scope, header, func(h req.Header) error {
+		return s.PostRequest(url, h, param, body, v)
+	})
+}
+
+// requestAsService send request with service token in authorization header,
+// a rejected token is dropped and the request is sent once more with a new token
+func (s *service) requestAsService(c *context.Context, audience, scope string, header req.Header, send func(h req.Header) error) error {
+	for attempt := 0; ; attempt++ {
+		accessToken, err := s.ServiceToken(c, audience, scope)
+		if err != nil {
+			return err
+		}
+
+		h := req.Header{}
+		for key, value := range header {
+			h[key] = value
+		}
+
+		h["Authorization"] = models.TokenTypeBearer + " " + accessToken
+		err = send(h)
+		if err != s.result.Internal.Unauthorized || attempt > 0 {
+			return err
+		}
+
+		tokens.mutex.Lock()
+		delete(tokens.items, audience+" "+scope)
+		tokens.mutex.Unlock()
+	}
+}
+
+// issueServiceToken issue service token of configured client like the client credentials grant,
+// the client must be active and allowed audience and scopes, all scopes of client are granted when none requested
+func (s *service) issueServiceToken(c *context.Context, audience, scope string) (*cachedToken, error) {
+	clientID := s.config.ServiceClient.ClientID
+	if clientID == "" {
+		return nil, errServiceClientNotConfigured
+	}
+
+	client, err := s.clientRepository.FindByClientID(c.GetDatabase(), clientID)
+	if err != nil {
+		logrus.Errorf("[issueServiceToken] find client by clientID=%s error: %s", clientID, err)
+		return nil, s.result.Internal.Unauthorized
+	}
+
+	if !client.IsActive || !client.Audiences.Contains(audience) {
+		logrus.Errorf("[issueServiceToken] audience=%s is not allowed for clientID=%s", audience, clientID)
+		return nil, s.result.Internal.Unauthorized
+	}
+
+	if scope == "" {
+		scope = strings.Join(client.Scopes, " ")
+	}
+
+	for _, sc := range strings.Fields(scope) {
+		if !client.Scopes.Contains(sc) {
+			logrus.Errorf("[issueServiceToken] scope=%s is not allowed for clientID=%s", sc, clientID)
+			return nil, s.result.Internal.Unauthorized
+		}
+	}
+
+	t, err := s.tokenService.CreateServiceToken(c, client.ClientID, audience, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	return &cachedToken{
+		accessToken: t.JWTToken,
+		expiresAt:   serviceTokenRefreshAt(time.Now(), s.config.JWT.ServiceTokenExpireTime),
+	}, nil
+}
+
+// serviceTokenRefreshAt time a token issued at now is renewed, the leeway is at most
+// half of its lifetime so short-lived tokens are still cached
+func serviceTokenRefreshAt(now time.Time, lifetime time.Duration) time.Time {
+	leeway := serviceTokenLeeway
+	if lifetime/2 < leeway {
+		leeway = lifetime / 2
+	}
+
+	return now.Add(lifetime - leeway)
+}
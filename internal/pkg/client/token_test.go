@@ -0,0 +1,29 @@
+package client
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestServiceTokenRefreshAt(t *testing.T) {
+	now := time.Now()
+	tests := []struct {
+		name     string
+		lifetime time.Duration
+		want     time.Time
+	}{
+		{name: "full leeway", lifetime: 5 * time.Minute, want: now.Add(4*time.Minute + 30*time.Second)},
+		{name: "leeway of one minute token", lifetime: time.Minute, want: now.Add(30 * time.Second)},
+		{name: "short-lived token", lifetime: 20 * time.Second, want: now.Add(10 * time.Second)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := serviceTokenRefreshAt(now, tt.lifetime)
+			assert.Equal(t, tt.want, got)
+			assert.True(t, got.After(now))
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package forwardauth
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const (
+	// HeaderUserID user id of verified token
+	HeaderUserID = "X-User-ID"
+	// HeaderUserRole user role of verified token
+	HeaderUserRole = "X-User-Role"
+	// HeaderSessionID session id of verified token
+	HeaderSessionID = "X-Session-ID"
+	// HeaderScopes space separated scopes of verified token
+	HeaderScopes = "X-Scopes"
+	// HeaderClientID oauth client of verified token
+	HeaderClientID = "X-Client-ID"
+	// HeaderActorID admin impersonating user of verified token
+	HeaderActorID = "X-Actor-ID"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	Verify(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config  *config.Configs
+	result  *config.ReturnResult
+	service Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:  config.CF,
+		result:  config.RR,
+		service: NewService(),
+	}
+}
+
+// Verify verify token for gateway
+// @Tags Auth
+// @Summary Forward auth
+// @Description Verify bearer token for the gateway before routing, identity of token is returned in X-User-ID, X-User-Role, X-Session-ID, X-Scopes, X-Client-ID and X-Actor-ID (impersonating admin) headers.
+// @Description Invalid tokens are 401 and tokens of another user are 403, so gateways deny the request.
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param Source header string false "application of token"
+// @Success 200
+// @Failure 401 {object} models.Message
+// @Failure 403 {object} models.Message
+// @Failure 500 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /auth/verify [get]
+func (ep *endpoint) Verify(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	identity, err := ep.service.Verify(context.WithContext(c))
+	if err != nil {
+		result, ok := err.(config.Result)
+		switch {
+		case !ok:
+			result = ep.result.Internal.ConnectionError
+			c.Status(fiber.StatusInternalServerError)
+		case result == ep.result.InvalidPermissionRole:
+			c.Status(fiber.StatusForbidden)
+		default:
+			c.Status(fiber.StatusUnauthorized)
+		}
+
+		return c.JSON(result.WithLocale(c))
+	}
+
+	c.Set(HeaderUserID, fmt.Sprintf("%d", identity.UserID))
+	c.Set(HeaderUserRole, fmt.Sprintf("%d", identity.Role))
+	c.Set(HeaderSessionID, identity.SessionID)
+	c.Set(HeaderScopes, identity.Scope)
+	c.Set(HeaderClientID, identity.ClientID)
+	actorID := ""
+	if identity.ActorID != 0 {
+		actorID = fmt.Sprintf("%d", identity.ActorID)
+	}
+
+	c.Set(HeaderActorID, actorID)
+	return c.SendStatus(fiber.StatusOK)
+}
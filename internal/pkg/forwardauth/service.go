@@ -0,0 +1,39 @@
+// Package forwardauth is a gateway forward auth package
+package forwardauth
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/token"
+)
+
+// Service service interface
+type Service interface {
+	Verify(c *context.Context) (*models.Identity, error)
+}
+
+type service struct {
+	config       *config.Configs
+	result       *config.ReturnResult
+	tokenService token.Service
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config:       config.CF,
+		result:       config.RR,
+		tokenService: token.NewService(),
+	}
+}
+
+// Verify verify bearer token of request forwarded by the gateway
+func (s *service) Verify(c *context.Context) (*models.Identity, error) {
+	accessToken := c.GetBearerToken()
+	if accessToken == "" {
+		return nil, s.result.InvalidToken
+	}
+
+	return s.tokenService.Verify(c, accessToken)
+}
@@ -15,6 +15,16 @@ type Endpoint interface {
 	Register(c *fiber.Ctx) error
 	Login(c *fiber.Ctx) error
 	RenewToken(c *fiber.Ctx) error
+	VerifyEmail(c *fiber.Ctx) error
+	ResendEmailVerification(c *fiber.Ctx) error
+	ForgotPassword(c *fiber.Ctx) error
+	ResetPassword(c *fiber.Ctx) error
+	RequestPhoneCode(c *fiber.Ctx) error
+	VerifyPhoneCode(c *fiber.Ctx) error
+	BeginPasskeyLogin(c *fiber.Ctx) error
+	FinishPasskeyLogin(c *fiber.Ctx) error
+	RequestMagicLink(c *fiber.Ctx) error
+	VerifyMagicLink(c *fiber.Ctx) error
 }
 
 type endpoint struct {
@@ -87,3 +97,182 @@ func (ep *endpoint) Login(c *fiber.Ctx) error {
 func (ep *endpoint) RenewToken(c *fiber.Ctx) error {
 	return handlers.ResponseObject(c, ep.tokenService.RenewToken, &request.RefreshTokenRequest{})
 }
+
+// VerifyEmail verify email
+// @Tags Guest
+// @Summary VerifyEmail
+// @Description Verify email by token of verification link or by email and code
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.VerifyEmailRequest true "request body"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/email/verify [post]
+func (ep *endpoint) VerifyEmail(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.VerifyEmail, &request.VerifyEmailRequest{})
+}
+
+// ResendEmailVerification resend email verification
+// @Tags Guest
+// @Summary ResendEmailVerification
+// @Description Resend verification email
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.EmailRequest true "request body"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/email/resend [post]
+func (ep *endpoint) ResendEmailVerification(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.ResendEmailVerification, &request.EmailRequest{})
+}
+
+// ForgotPassword forgot password
+// @Tags Guest
+// @Summary ForgotPassword
+// @Description Send password reset code and link to email
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.EmailRequest true "request body"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/password/forgot [post]
+func (ep *endpoint) ForgotPassword(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.ForgotPassword, &request.EmailRequest{})
+}
+
+// ResetPassword reset password
+// @Tags Guest
+// @Summary ResetPassword
+// @Description Reset password by token of reset link or by email and otp, every session is signed out
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.ResetPassword true "request body"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/password/reset [post]
+func (ep *endpoint) ResetPassword(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.ResetPassword, &request.ResetPassword{})
+}
+
+// RequestPhoneCode request phone login code
+// @Tags Guest
+// @Summary RequestPhoneCode
+// @Description Send login code to phone number by sms
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.PhoneCodeRequest true "request body"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/phone/code [post]
+func (ep *endpoint) RequestPhoneCode(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.RequestPhoneCode, &request.PhoneCodeRequest{})
+}
+
+// VerifyPhoneCode verify phone login code
+// @Tags Guest
+// @Summary VerifyPhoneCode
+// @Description Sign in by code sent to phone number, account is created on first sign in
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.PhoneLoginRequest true "request body"
+// @Success 200 {object} models.RefreshToken
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/phone/verify [post]
+func (ep *endpoint) VerifyPhoneCode(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.VerifyPhoneCode, &request.PhoneLoginRequest{})
+}
+
+// BeginPasskeyLogin begin passkey login
+// @Tags Guest
+// @Summary BeginPasskeyLogin
+// @Description Returns options for navigator.credentials.get and token of the ceremony to sign in by passkey
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {object} models.PasskeyOptions
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/passkey/login/begin [post]
+func (ep *endpoint) BeginPasskeyLogin(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.BeginPasskeyLogin)
+}
+
+// FinishPasskeyLogin finish passkey login
+// @Tags Guest
+// @Summary FinishPasskeyLogin
+// @Description Sign in by credential returned by navigator.credentials.get, users with 2FA get two_factor_token
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.PasskeyLoginRequest true "request body"
+// @Success 200 {object} models.RefreshToken
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/passkey/login/finish [post]
+func (ep *endpoint) FinishPasskeyLogin(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.FinishPasskeyLogin, &request.PasskeyLoginRequest{})
+}
+
+// RequestMagicLink request magic link
+// @Tags Guest
+// @Summary RequestMagicLink
+// @Description Send single-use sign in link to email, with bind_device the returned device_token is needed to sign in without a confirmation code
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.MagicLinkRequest true "request body"
+// @Success 200 {object} models.MagicLink
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/magic-link [post]
+func (ep *endpoint) RequestMagicLink(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.RequestMagicLink, &request.MagicLinkRequest{})
+}
+
+// VerifyMagicLink verify magic link
+// @Tags Guest
+// @Summary VerifyMagicLink
+// @Description Sign in by token of link sent to email, a link opened on another device than it was requested on needs the code mailed when it was first opened
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.MagicLinkLoginRequest true "request body"
+// @Success 200 {object} models.RefreshToken
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/magic-link/verify [post]
+func (ep *endpoint) VerifyMagicLink(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.VerifyMagicLink, &request.MagicLinkLoginRequest{})
+}
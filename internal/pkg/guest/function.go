@@ -1,12 +1,17 @@
 package guest
 
 import (
+	"crypto/hmac"
 	"ecommerce-authen/internal/core/bcrypt"
 	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/utils"
 	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/token"
 	"ecommerce-authen/internal/request"
 	"fmt"
+	"net/url"
 	"strings"
+	"time"
 
 	"firebase.google.com/go/auth"
 	"github.com/imroc/req"
@@ -186,6 +191,10 @@ func (s *service) loginNormal(c *context.Context, request *request.LoginRequest)
 		return nil, s.result.InvalidPassword
 	}
 
+	if user.PasswordResetRequired {
+		return nil, s.result.PleaseChangePassword
+	}
+
 	return user, nil
 }
 
@@ -219,10 +228,250 @@ func (s *service) CreateUserProfile(c *context.Context, profile *models.Profile)
 
 	response := &models.Message{}
 	url := fmt.Sprintf("%s%s", s.config.User.URL, s.config.User.Path.Profile)
-	err := s.clientService.PostRequest(url, header, nil, profile, response)
+	err := s.clientService.PostRequestAsService(c, s.config.User.Audience, s.config.User.Scope, url, header, nil, profile, response)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// sendEmailCode send code and link of purpose to user email
+func (s *service) sendEmailCode(p purpose, user *models.User, expireTime time.Duration, linkURL, subject, action string) error {
+	code, err := s.otp.GenerateRandomCode(s.config.VerificationCode.Length)
+	if err != nil {
+		logrus.Errorf("generate %s code error: %s", p, err)
+		return err
+	}
+
+	linkToken, err := utils.RandomToken(32)
+	if err != nil {
+		logrus.Errorf("generate %s token error: %s", p, err)
+		return err
+	}
+
+	v := &emailCode{
+		Email:     user.Email,
+		CodeHash:  p.codeHash(user.Email, code),
+		TokenHash: token.Hash(linkToken),
+	}
+
+	if err := s.storeEmailCode(p, user.ID, v, expireTime); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s?token=%s", linkURL, url.QueryEscape(linkToken))
+	body := fmt.Sprintf(
+		`<p>Your code is <b>%s</b>.</p><p>Or %s by <a href="%s">this link</a>.</p>`,
+		code, action, link,
+	)
+
+	return s.mailSender.Send(user.Email, subject, body)
+}
+
+// findUserByEmailCode find user of link token or of email and code,
+// wrong codes are counted against the code and the email, the email is locked out
+// after too many of them, and the code is deleted by caller once it was used
+func (s *service) findUserByEmailCode(c *context.Context, p purpose, email, code, linkToken string) (*models.User, *emailCode, error) {
+	db := c.GetDatabase()
+	user := &models.User{}
+	if linkToken != "" {
+		userID, err := s.findEmailCodeUser(p, linkToken)
+		if err != nil {
+			return nil, nil, s.result.InvalidCodeOrExpired
+		}
+
+		if err := s.userRepository.FindOneObjectByIDUInt(db, userID, user); err != nil {
+			logrus.Errorf("find user by id=%d error: %s", userID, err)
+			return nil, nil, s.result.InvalidCodeOrExpired
+		}
+	} else {
+		email = strings.ToLower(email)
+		if s.lockedOut(p, email) {
+			return nil, nil, s.result.ReachLimit
+		}
+
+		exists, err := s.userRepository.FindEmail(db, email)
+		if err != nil {
+			return nil, nil, s.result.InvalidCodeOrExpired
+		}
+
+		user = exists
+	}
+
+	v, err := s.findEmailCode(p, user.ID)
+	if err != nil || v.Email != user.Email {
+		return nil, nil, s.result.InvalidCodeOrExpired
+	}
+
+	if linkToken == "" {
+		if v.Attempts >= maxCodeAttempts {
+			return nil, nil, s.result.ReachLimit
+		}
+
+		if !hmac.Equal([]byte(p.codeHash(v.Email, code)), []byte(v.CodeHash)) {
+			s.countEmailCodeAttempt(p, user.ID, v)
+			s.countFailure(p, email)
+			return nil, nil, s.result.InvalidOTP
+		}
+
+		s.clearFailures(p, email)
+	}
+
+	return user, v, nil
+}
+
+// sendEmailVerification send verification code and link to user email
+func (s *service) sendEmailVerification(user *models.User) error {
+	if user.Email == "" {
+		return nil
+	}
+
+	cf := s.config.EmailVerification
+	return s.sendEmailCode(purposeEmailVerification, user, cf.ExpireTime, cf.URL, "Verify your email", "verify your email")
+}
+
+// sendPasswordReset send password reset code and link to user email
+func (s *service) sendPasswordReset(user *models.User) error {
+	cf := s.config.PasswordReset
+	return s.sendEmailCode(purposePasswordReset, user, cf.ExpireTime, cf.URL, "Reset your password", "reset your password")
+}
+
+// signMagicLinkToken signed token of link from random nonce,
+// tokens that were not issued here are refused before redis is asked
+func signMagicLinkToken(nonce string) string {
+	return nonce + "." + token.Hash(string(purposeMagicLink)+":"+nonce)
+}
+
+// validMagicLinkToken token of link has valid signature
+func validMagicLinkToken(linkToken string) bool {
+	i := strings.LastIndex(linkToken, ".")
+	if i <= 0 {
+		return false
+	}
+
+	return hmac.Equal([]byte(signMagicLinkToken(linkToken[:i])), []byte(linkToken))
+}
+
+// sameDevice device token is of device that requested the link
+func sameDevice(deviceToken, deviceHash string) bool {
+	if deviceToken == "" {
+		return false
+	}
+
+	return hmac.Equal([]byte(token.Hash(deviceToken)), []byte(deviceHash))
+}
+
+// sendMagicLink send sign in link to user email, bound to device of hash when it is not empty
+func (s *service) sendMagicLink(user *models.User, deviceHash string) error {
+	nonce, err := utils.RandomToken(32)
+	if err != nil {
+		logrus.Errorf("generate magic link token error: %s", err)
+		return err
+	}
+
+	linkToken := signMagicLinkToken(nonce)
+	v := &magicLink{
+		UserID:     user.ID,
+		Email:      user.Email,
+		DeviceHash: deviceHash,
+	}
+
+	if err := s.storeMagicLink(linkToken, v); err != nil {
+		return err
+	}
+
+	link := fmt.Sprintf("%s?token=%s", s.config.MagicLink.URL, url.QueryEscape(linkToken))
+	body := fmt.Sprintf(
+		`<p>Sign in by <a href="%s">this link</a>, it expires in %s and can only be used once.</p>`+
+			`<p>If you did not request it, you can ignore this email.</p>`,
+		link, s.config.MagicLink.ExpireTime,
+	)
+
+	return s.mailSender.Send(user.Email, "Your sign in link", body)
+}
+
+// sendMagicLinkConfirmation send code confirming sign in by link opened on another device,
+// only the first opening sends one so the link can not be used to flood the mailbox
+func (s *service) sendMagicLinkConfirmation(linkToken string, v *magicLink) error {
+	code, err := s.otp.GenerateRandomCode(s.config.VerificationCode.Length)
 	if err != nil {
+		logrus.Errorf("generate magic link confirmation code error: %s", err)
+		return err
+	}
+
+	stored, err := s.storeMagicLinkConfirmation(linkToken, purposeMagicLink.codeHash(v.Email, code))
+	if err != nil || !stored {
+		return err
+	}
+
+	body := fmt.Sprintf(
+		`<p>Your sign in link was opened on another device than it was requested on. Enter code <b>%s</b> on that device to sign in.</p>`+
+			`<p>If it was not you, do not share this code.</p>`,
+		code,
+	)
+
+	return s.mailSender.Send(v.Email, "Confirm your sign in", body)
+}
+
+// findPhoneUser find user of phone number, nil when there is none
+func (s *service) findPhoneUser(c *context.Context, phoneNumber string) (*models.User, error) {
+	user, err := s.userRepository.FindPhoneNumber(c.GetDatabase(), phoneNumber)
+	if err != nil {
+		if err.Error() == gorm.ErrRecordNotFound.Error() {
+			return nil, nil
+		}
+
+		logrus.Errorf("find user by phone number error: %s", err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// createPhoneUser create user of verified phone number
+func (s *service) createPhoneUser(c *context.Context, phoneNumber string) (*models.User, error) {
+	now := time.Now()
+	user := &models.User{
+		PhoneNumber:     phoneNumber,
+		PhoneVerifiedAt: &now,
+	}
+
+	if err := s.userRepository.Create(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("create user error: %s", err)
+		return nil, err
+	}
+
+	if err := s.CreateUserProfile(c, &models.Profile{ID: user.ID}); err != nil {
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// verifyPhoneUser mark phone number of user verified
+func (s *service) verifyPhoneUser(c *context.Context, user *models.User) error {
+	now := time.Now()
+	user.PhoneVerifiedAt = &now
+	if err := s.userRepository.Update(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("update phone verified on userID=%d error: %s", user.ID, err)
 		return err
 	}
 
 	return nil
 }
+
+// signIn create session of user after first step of sign in,
+// second step and change of expired password come first when required
+func (s *service) signIn(c *context.Context, user *models.User, loginType models.LoginType) (*models.RefreshToken, error) {
+	grant := token.Grant{LoginType: loginType}
+	if user.TwoFactorEnabled() {
+		return s.twoFactorService.Challenge(user, grant)
+	}
+
+	if loginType == models.LoginTypeNormal && s.passwordService.Expired(user) {
+		return s.passwordService.IssueChangeToken(user)
+	}
+
+	return s.tokenService.Create(c, user, grant)
+}
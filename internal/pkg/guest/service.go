@@ -1,21 +1,32 @@
 package guest
 
 import (
+	"crypto/hmac"
+	"ecommerce-authen/internal/core/audit"
 	"ecommerce-authen/internal/core/bcrypt"
 	"ecommerce-authen/internal/core/config"
 	"ecommerce-authen/internal/core/context"
 	"ecommerce-authen/internal/core/facebook"
 	"ecommerce-authen/internal/core/firebaseauth"
+	"ecommerce-authen/internal/core/mail"
+	"ecommerce-authen/internal/core/otp"
+	"ecommerce-authen/internal/core/sms"
 	"ecommerce-authen/internal/core/utils"
 	"ecommerce-authen/internal/repositories"
 	"ecommerce-authen/internal/request"
+	"fmt"
 	"strings"
 	"sync"
+	"time"
 
 	"ecommerce-authen/internal/models"
 	"ecommerce-authen/internal/pkg/client"
+	"ecommerce-authen/internal/pkg/passkey"
+	"ecommerce-authen/internal/pkg/password"
 	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/pkg/twofactor"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/jinzhu/copier"
 	"github.com/sirupsen/logrus"
 	"gorm.io/gorm"
@@ -25,29 +36,51 @@ import (
 type Service interface {
 	Register(c *context.Context, request *request.RegisterRequest) (*models.RefreshToken, error)
 	Login(c *context.Context, request *request.LoginRequest) (*models.RefreshToken, error)
+	VerifyEmail(c *context.Context, request *request.VerifyEmailRequest) error
+	ResendEmailVerification(c *context.Context, request *request.EmailRequest) error
+	ForgotPassword(c *context.Context, request *request.EmailRequest) error
+	ResetPassword(c *context.Context, request *request.ResetPassword) error
+	RequestPhoneCode(c *context.Context, request *request.PhoneCodeRequest) error
+	VerifyPhoneCode(c *context.Context, request *request.PhoneLoginRequest) (*models.RefreshToken, error)
+	BeginPasskeyLogin(c *context.Context) (*models.PasskeyOptions, error)
+	FinishPasskeyLogin(c *context.Context, request *request.PasskeyLoginRequest) (*models.RefreshToken, error)
+	RequestMagicLink(c *context.Context, request *request.MagicLinkRequest) (*models.MagicLink, error)
+	VerifyMagicLink(c *context.Context, request *request.MagicLinkLoginRequest) (*models.RefreshToken, error)
 }
 
 type service struct {
-	config          *config.Configs
-	result          *config.ReturnResult
-	userRepository  repositories.UserRepository
-	tokenService    token.Service
-	firebaseService firebaseauth.Client
-	clientService   client.Service
-	facebookService facebook.FacebookService
-	mutex           sync.Mutex
+	config           *config.Configs
+	result           *config.ReturnResult
+	userRepository   repositories.UserRepository
+	tokenService     token.Service
+	firebaseService  firebaseauth.Client
+	clientService    client.Service
+	facebookService  facebook.FacebookService
+	otp              otp.Interface
+	mailSender       mail.Sender
+	passwordService  password.Service
+	twoFactorService twofactor.Service
+	passkeyService   passkey.Service
+	smsProvider      sms.SMSProvider
+	mutex            sync.Mutex
 }
 
 // NewService new service
 func NewService() Service {
 	return &service{
-		config:          config.CF,
-		result:          config.RR,
-		userRepository:  repositories.UserNewRepository(),
-		tokenService:    token.NewService(),
-		firebaseService: firebaseauth.New(),
-		facebookService: facebook.New(),
-		clientService:   client.NewService(),
+		config:           config.CF,
+		result:           config.RR,
+		userRepository:   repositories.UserNewRepository(),
+		tokenService:     token.NewService(),
+		firebaseService:  firebaseauth.New(),
+		facebookService:  facebook.New(),
+		clientService:    client.NewService(),
+		otp:              otp.New(),
+		mailSender:       mail.New(),
+		passwordService:  password.NewService(),
+		smsProvider:      sms.New(),
+		twoFactorService: twofactor.NewService(),
+		passkeyService:   passkey.NewService(),
 	}
 }
 
@@ -103,9 +136,11 @@ func (s *service) Register(c *context.Context, request *request.RegisterRequest)
 		return nil, err
 	}
 
+	now := time.Now()
 	user := &models.User{}
 	_ = copier.Copy(user, request)
 	user.Password = passwordHash
+	user.PasswordChangedAt = &now
 	err = s.userRepository.Create(db, user)
 	if err != nil {
 		logrus.Errorf("create user error: %s", err)
@@ -124,7 +159,17 @@ func (s *service) Register(c *context.Context, request *request.RegisterRequest)
 		return nil, err
 	}
 
-	token, err := s.tokenService.Create(c, user)
+	s.allowResend(purposeEmailVerification, user.Email, s.config.EmailVerification.ResendInterval)
+	if err := s.sendEmailVerification(user); err != nil {
+		logrus.Errorf("send email verification to userID=%d error: %s", user.ID, err)
+	}
+
+	// user has to verify email and sign in again
+	if s.config.EmailVerification.Required {
+		return &models.RefreshToken{UserID: user.ID, Role: user.Role}, nil
+	}
+
+	token, err := s.tokenService.Create(c, user, token.Grant{LoginType: models.LoginTypeNormal})
 	if err != nil {
 		return nil, err
 	}
@@ -148,10 +193,420 @@ func (s *service) Login(c *context.Context, request *request.LoginRequest) (*mod
 		return nil, err
 	}
 
-	token, err := s.tokenService.Create(c, user)
+	if user.Deactivated() {
+		return nil, s.result.BlockedUser
+	}
+
+	loginType := request.LoginType
+	if loginType != models.LoginTypeGoogle && loginType != models.LoginTypeFacebook {
+		loginType = models.LoginTypeNormal
+	}
+
+	if loginType == models.LoginTypeNormal && s.config.EmailVerification.Required && !user.EmailVerified() {
+		return nil, s.result.EmailNotVerified
+	}
+
+	return s.signIn(c, user, loginType)
+}
+
+// VerifyEmail verify email by link token or by code sent to email
+func (s *service) VerifyEmail(c *context.Context, request *request.VerifyEmailRequest) error {
+	if !s.allowIP(purposeEmailVerification, c.IP()) {
+		return s.result.ReachLimit
+	}
+
+	user, v, err := s.findUserByEmailCode(c, purposeEmailVerification, request.Email, request.Code, request.Token)
+	if err != nil {
+		return err
+	}
+
+	if user.EmailVerified() {
+		s.deleteEmailCode(purposeEmailVerification, user.ID, v)
+		return s.result.AlreadyVerified
+	}
+
+	now := time.Now()
+	user.EmailVerifiedAt = &now
+	if err := s.userRepository.Update(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("update email verified on userID=%d error: %s", user.ID, err)
+		return err
+	}
+
+	s.deleteEmailCode(purposeEmailVerification, user.ID, v)
+	return nil
+}
+
+// ResendEmailVerification send new verification email,
+// unknown and verified emails are not reported so emails can not be enumerated
+func (s *service) ResendEmailVerification(c *context.Context, request *request.EmailRequest) error {
+	email := strings.ToLower(request.Email)
+	if !utils.IsValidEmail(email) {
+		return s.result.InvalidEmail
+	}
+
+	if !s.allowIP(purposeEmailVerification, c.IP()) {
+		return s.result.ReachLimit
+	}
+
+	if s.lockedOut(purposeEmailVerification, email) {
+		return s.result.ReachLimit
+	}
+
+	if !s.allowResend(purposeEmailVerification, email, s.config.EmailVerification.ResendInterval) {
+		return s.result.ReachLimit
+	}
+
+	user, err := s.userRepository.FindEmail(c.GetDatabase(), email)
+	if err != nil {
+		if err.Error() != gorm.ErrRecordNotFound.Error() {
+			logrus.Errorf("find user by email error: %s", err)
+			return err
+		}
+
+		return nil
+	}
+
+	if user.EmailVerified() {
+		return nil
+	}
+
+	return s.sendEmailVerification(user)
+}
+
+// ForgotPassword send password reset code and link to email,
+// unknown emails are not reported so emails can not be enumerated
+func (s *service) ForgotPassword(c *context.Context, request *request.EmailRequest) error {
+	email := strings.ToLower(request.Email)
+	if !utils.IsValidEmail(email) {
+		return s.result.InvalidEmail
+	}
+
+	if !s.allowIP(purposePasswordReset, c.IP()) {
+		return s.result.ReachLimit
+	}
+
+	if s.lockedOut(purposePasswordReset, email) {
+		return s.result.ReachLimit
+	}
+
+	if !s.allowResend(purposePasswordReset, email, s.config.PasswordReset.ResendInterval) {
+		return s.result.ReachLimit
+	}
+
+	user, err := s.userRepository.FindEmail(c.GetDatabase(), email)
+	if err != nil {
+		if err.Error() != gorm.ErrRecordNotFound.Error() {
+			logrus.Errorf("find user by email error: %s", err)
+			return err
+		}
+
+		return nil
+	}
+
+	if user.Deactivated() {
+		return nil
+	}
+
+	return s.sendPasswordReset(user)
+}
+
+// ResetPassword set new password by link token or by code sent to email,
+// every session of user is signed out
+func (s *service) ResetPassword(c *context.Context, request *request.ResetPassword) error {
+	if request.Password != request.ConfirmPassword {
+		return s.result.PasswordNotMatch
+	}
+
+	if !utils.IsValidPassword(request.Password) {
+		return s.result.InvalidPassword
+	}
+
+	if !s.allowIP(purposePasswordReset, c.IP()) {
+		return s.result.ReachLimit
+	}
+
+	user, v, err := s.findUserByEmailCode(c, purposePasswordReset, request.Email, request.Otp, request.Token)
+	if err != nil {
+		return err
+	}
+
+	if user.Deactivated() {
+		return s.result.BlockedUser
+	}
+
+	// the code was received by email so the email is verified too
+	now := time.Now()
+	if user.EmailVerifiedAt == nil {
+		user.EmailVerifiedAt = &now
+	}
+
+	if err := s.passwordService.SetPassword(c, user, request.Password); err != nil {
+		return err
+	}
+
+	s.deleteEmailCode(purposePasswordReset, user.ID, v)
+	if err := s.tokenService.RevokeSessions(c, user.ID, ""); err != nil {
+		return err
+	}
+
+	audit.Security(audit.EventPasswordReset, logrus.Fields{
+		"user_id":    user.ID,
+		"ip":         c.IP(),
+		"user_agent": c.Get(fiber.HeaderUserAgent),
+	})
+
+	return nil
+}
+
+// RequestPhoneCode send login code to phone number,
+// unknown numbers get a code too as accounts are created on first verify
+func (s *service) RequestPhoneCode(c *context.Context, request *request.PhoneCodeRequest) error {
+	if !utils.IsValidPhoneNumber(request.PhoneNumber) {
+		return s.result.InvalidPhoneNumber
+	}
+
+	if !s.allowIP(purposePhoneLogin, c.IP()) {
+		return s.result.ReachLimit
+	}
+
+	if s.lockedOut(purposePhoneLogin, request.PhoneNumber) {
+		return s.result.ReachLimit
+	}
+
+	if !s.allowResend(purposePhoneLogin, request.PhoneNumber, s.config.PhoneLogin.ResendInterval) {
+		return s.result.ReachLimit
+	}
+
+	code, err := s.otp.GenerateRandomCode(s.config.VerificationCode.Length)
+	if err != nil {
+		logrus.Errorf("generate phone code error: %s", err)
+		return err
+	}
+
+	v := &phoneCode{CodeHash: purposePhoneLogin.codeHash(request.PhoneNumber, code)}
+	if err := s.storePhoneCode(request.PhoneNumber, v); err != nil {
+		return err
+	}
+
+	message := fmt.Sprintf("Your login code is %s", code)
+	if err := s.smsProvider.Send(request.PhoneNumber, message); err != nil {
+		logrus.Errorf("send phone code error: %s", err)
+		return s.result.Internal.ConnectionError
+	}
+
+	return nil
+}
+
+// VerifyPhoneCode sign in by code sent to phone number, account of phone number
+// is created on first sign in and an account with the number unverified needs its password
+func (s *service) VerifyPhoneCode(c *context.Context, request *request.PhoneLoginRequest) (*models.RefreshToken, error) {
+	if !utils.IsValidPhoneNumber(request.PhoneNumber) {
+		return nil, s.result.InvalidPhoneNumber
+	}
+
+	if !s.allowIP(purposePhoneLogin, c.IP()) {
+		return nil, s.result.ReachLimit
+	}
+
+	if s.lockedOut(purposePhoneLogin, request.PhoneNumber) {
+		return nil, s.result.ReachLimit
+	}
+
+	v, err := s.findPhoneCode(request.PhoneNumber)
+	if err != nil {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	if v.Attempts >= maxCodeAttempts {
+		return nil, s.result.ReachLimit
+	}
+
+	codeHash := purposePhoneLogin.codeHash(request.PhoneNumber, request.Code)
+	if !hmac.Equal([]byte(codeHash), []byte(v.CodeHash)) {
+		s.countPhoneCodeAttempt(request.PhoneNumber, v)
+		s.countFailure(purposePhoneLogin, request.PhoneNumber)
+		return nil, s.result.InvalidOTP
+	}
+
+	user, err := s.findPhoneUser(c, request.PhoneNumber)
 	if err != nil {
 		return nil, err
 	}
 
-	return token, nil
+	// an account registered with the number never proved to own it,
+	// its password is required before the number signs in to it
+	if user != nil && user.PhoneVerifiedAt == nil {
+		if request.Password == "" {
+			return nil, s.result.PhonePasswordRequired
+		}
+
+		if user.Password == "" || !bcrypt.ComparePassword(user.Password, request.Password) {
+			s.countPhoneCodeAttempt(request.PhoneNumber, v)
+			s.countFailure(purposePhoneLogin, request.PhoneNumber)
+			return nil, s.result.InvalidPassword
+		}
+	}
+
+	s.clearFailures(purposePhoneLogin, request.PhoneNumber)
+	s.deletePhoneCode(request.PhoneNumber)
+	if user == nil {
+		user, err = s.createPhoneUser(c, request.PhoneNumber)
+		if err != nil {
+			return nil, err
+		}
+	} else if user.PhoneVerifiedAt == nil {
+		if err := s.verifyPhoneUser(c, user); err != nil {
+			return nil, err
+		}
+	}
+
+	if user.Deactivated() {
+		return nil, s.result.BlockedUser
+	}
+
+	return s.signIn(c, user, models.LoginTypePhone)
+}
+
+// BeginPasskeyLogin start passwordless login ceremony of passkey
+func (s *service) BeginPasskeyLogin(c *context.Context) (*models.PasskeyOptions, error) {
+	return s.passkeyService.BeginLogin(c)
+}
+
+// FinishPasskeyLogin sign in by assertion of passkey
+func (s *service) FinishPasskeyLogin(c *context.Context, request *request.PasskeyLoginRequest) (*models.RefreshToken, error) {
+	user, err := s.passkeyService.FinishLogin(c, request)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.signIn(c, user, models.LoginTypePasskey)
+}
+
+// RequestMagicLink send sign in link to email, unknown emails are not reported
+// so emails can not be enumerated, device token is returned for them too
+func (s *service) RequestMagicLink(c *context.Context, request *request.MagicLinkRequest) (*models.MagicLink, error) {
+	email := strings.ToLower(request.Email)
+	if !utils.IsValidEmail(email) {
+		return nil, s.result.InvalidEmail
+	}
+
+	if !s.allowIP(purposeMagicLink, c.IP()) {
+		return nil, s.result.ReachLimit
+	}
+
+	if !s.allowResend(purposeMagicLink, email, s.config.MagicLink.ResendInterval) {
+		return nil, s.result.ReachLimit
+	}
+
+	response := &models.MagicLink{}
+	deviceHash := ""
+	if request.BindDevice {
+		deviceToken, err := utils.RandomToken(32)
+		if err != nil {
+			logrus.Errorf("generate magic link device token error: %s", err)
+			return nil, err
+		}
+
+		response.DeviceToken = deviceToken
+		deviceHash = token.Hash(deviceToken)
+	}
+
+	user, err := s.userRepository.FindEmail(c.GetDatabase(), email)
+	if err != nil {
+		if err.Error() != gorm.ErrRecordNotFound.Error() {
+			logrus.Errorf("find user by email error: %s", err)
+			return nil, err
+		}
+
+		return response, nil
+	}
+
+	if user.Deactivated() {
+		return response, nil
+	}
+
+	if err := s.sendMagicLink(user, deviceHash); err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+// VerifyMagicLink sign in by token of link sent to email, a link bound to another device
+// is only used with the confirmation code mailed when it was first opened here
+func (s *service) VerifyMagicLink(c *context.Context, request *request.MagicLinkLoginRequest) (*models.RefreshToken, error) {
+	if !validMagicLinkToken(request.Token) {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	v, err := s.findMagicLink(request.Token)
+	if err != nil {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	if s.lockedOut(purposeMagicLink, v.Email) {
+		return nil, s.result.ReachLimit
+	}
+
+	otherDevice := v.DeviceHash != "" && !sameDevice(request.DeviceToken, v.DeviceHash)
+	if otherDevice {
+		if request.Code == "" {
+			if err := s.sendMagicLinkConfirmation(request.Token, v); err != nil {
+				return nil, err
+			}
+
+			return nil, s.result.MagicLinkConfirmRequired
+		}
+
+		codeHash, err := s.findMagicLinkConfirmation(request.Token)
+		if err != nil {
+			return nil, s.result.InvalidCodeOrExpired
+		}
+
+		if !hmac.Equal([]byte(purposeMagicLink.codeHash(v.Email, request.Code)), []byte(codeHash)) {
+			s.countFailure(purposeMagicLink, v.Email)
+			return nil, s.result.InvalidOTP
+		}
+	}
+
+	v, err = s.claimMagicLink(request.Token)
+	if err != nil {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	s.clearFailures(purposeMagicLink, v.Email)
+	db := c.GetDatabase()
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(db, v.UserID, user); err != nil {
+		logrus.Errorf("find user by id=%d error: %s", v.UserID, err)
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	if user.Email != v.Email {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	if user.Deactivated() {
+		return nil, s.result.BlockedUser
+	}
+
+	// the link was received by email so the email is verified too
+	if user.EmailVerifiedAt == nil {
+		now := time.Now()
+		user.EmailVerifiedAt = &now
+		if err := s.userRepository.Update(db, user); err != nil {
+			logrus.Errorf("update email verified on userID=%d error: %s", user.ID, err)
+			return nil, err
+		}
+	}
+
+	if otherDevice {
+		audit.Security(audit.EventMagicLinkConfirmed, logrus.Fields{
+			"user_id":    user.ID,
+			"ip":         c.IP(),
+			"user_agent": c.Get(fiber.HeaderUserAgent),
+		})
+	}
+
+	return s.signIn(c, user, models.LoginTypeMagicLink)
 }
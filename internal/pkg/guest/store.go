@@ -0,0 +1,327 @@
+package guest
+
+import (
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/pkg/token"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// purpose what a code sent by email is for, used as prefix of redis keys
+type purpose string
+
+const (
+	purposeEmailVerification purpose = "email_verification"
+	purposePasswordReset     purpose = "password_reset"
+	purposePhoneLogin        purpose = "phone_login"
+	purposeMagicLink         purpose = "magic_link"
+)
+
+const (
+	// maxCodeAttempts wrong codes allowed before a new code must be sent
+	maxCodeAttempts = 5
+)
+
+// emailCode pending code sent to email of user, used by otp code or link token
+type emailCode struct {
+	Email     string
+	CodeHash  string
+	TokenHash string
+	Attempts  int
+}
+
+// codeKey redis key of code of user
+func (p purpose) codeKey(userID uint) string {
+	return fmt.Sprintf("%s:%d", p, userID)
+}
+
+// tokenKey redis key of link token hash
+func (p purpose) tokenKey(tokenHash string) string {
+	return fmt.Sprintf("%s_token:%s", p, tokenHash)
+}
+
+// confirmKey redis key of confirmation code of link token hash
+func (p purpose) confirmKey(tokenHash string) string {
+	return fmt.Sprintf("%s_confirm:%s", p, tokenHash)
+}
+
+// resendKey redis key of resend throttle of email or phone number
+func (p purpose) resendKey(recipient string) string {
+	return fmt.Sprintf("%s_resend:%s", p, token.Hash(recipient))
+}
+
+// failureKey redis key of wrong codes of email or phone number, kept across resends
+func (p purpose) failureKey(recipient string) string {
+	return fmt.Sprintf("%s_failure:%s", p, token.Hash(recipient))
+}
+
+// ipKey redis key of requests of ip address
+func (p purpose) ipKey(ip string) string {
+	return fmt.Sprintf("%s_ip:%s", p, token.Hash(ip))
+}
+
+// codeHash keyed hash of code sent to recipient, codes are never stored in plain text
+func (p purpose) codeHash(recipient, code string) string {
+	return token.Hash(fmt.Sprintf("%s:%s:%s", p, recipient, code))
+}
+
+// phoneKey redis key of code sent to phone number
+func (p purpose) phoneKey(phoneNumber string) string {
+	return fmt.Sprintf("%s:%s", p, token.Hash(phoneNumber))
+}
+
+// storeEmailCode store code and its link token until it expires,
+// link of previous code is not valid anymore
+func (s *service) storeEmailCode(p purpose, userID uint, v *emailCode, expireTime time.Duration) error {
+	conn := redis.GetConnection()
+	previous, err := s.findEmailCode(p, userID)
+	if err == nil && previous.TokenHash != "" {
+		_ = conn.Delete(p.tokenKey(previous.TokenHash))
+	}
+
+	if err := conn.Set(p.codeKey(userID), v, expireTime); err != nil {
+		logrus.Errorf("set %s code error: %s", p, err)
+		return err
+	}
+
+	if err := conn.Set(p.tokenKey(v.TokenHash), userID, expireTime); err != nil {
+		logrus.Errorf("set %s token error: %s", p, err)
+		return err
+	}
+
+	return nil
+}
+
+// findEmailCode find code of user
+func (s *service) findEmailCode(p purpose, userID uint) (*emailCode, error) {
+	v := &emailCode{}
+	if err := redis.GetConnection().Get(p.codeKey(userID), v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// findEmailCodeUser find user of link token
+func (s *service) findEmailCodeUser(p purpose, linkToken string) (uint, error) {
+	var userID uint
+	if err := redis.GetConnection().Get(p.tokenKey(token.Hash(linkToken)), &userID); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// countEmailCodeAttempt count wrong code, code is kept until its original expiry
+func (s *service) countEmailCodeAttempt(p purpose, userID uint, v *emailCode) {
+	conn := redis.GetConnection()
+	v.Attempts++
+	ttl, err := conn.GetTTL(p.codeKey(userID))
+	if err != nil || ttl <= time.Second {
+		return
+	}
+
+	if err := conn.Set(p.codeKey(userID), v, ttl); err != nil {
+		logrus.Errorf("set %s code error: %s", p, err)
+	}
+}
+
+// deleteEmailCode delete code and its link token
+func (s *service) deleteEmailCode(p purpose, userID uint, v *emailCode) {
+	conn := redis.GetConnection()
+	if err := conn.Delete(p.tokenKey(v.TokenHash)); err != nil {
+		logrus.Errorf("delete %s token error: %s", p, err)
+	}
+
+	if err := conn.Delete(p.codeKey(userID)); err != nil {
+		logrus.Errorf("delete %s code error: %s", p, err)
+	}
+}
+
+// allowResend only one message per email address or phone number in resend interval
+func (s *service) allowResend(p purpose, recipient string, interval time.Duration) bool {
+	conn := redis.GetConnection()
+	key := p.resendKey(recipient)
+	if ttl, err := conn.GetTTL(key); err == nil && ttl > 0 {
+		return false
+	}
+
+	if err := conn.Set(key, true, interval); err != nil {
+		logrus.Errorf("set %s resend error: %s", p, err)
+	}
+
+	return true
+}
+
+// allowIP count request of ip address, only IP_MAX_REQUESTS requests per IP_WINDOW are allowed
+func (s *service) allowIP(p purpose, ip string) bool {
+	cf := s.config.VerificationCode
+	count, err := redis.GetConnection().Increment(p.ipKey(ip), cf.IPWindow)
+	if err != nil {
+		logrus.Errorf("increment %s ip error: %s", p, err)
+		return true
+	}
+
+	return count <= cf.IPMaxRequests
+}
+
+// lockedOut too many wrong codes of email or phone number, new codes do not reset it
+func (s *service) lockedOut(p purpose, recipient string) bool {
+	count, err := redis.GetConnection().GetCounter(p.failureKey(recipient))
+	if err != nil {
+		logrus.Errorf("get %s failure error: %s", p, err)
+		return false
+	}
+
+	return count >= s.config.VerificationCode.MaxFailures
+}
+
+// countFailure count wrong code of email or phone number until lockout time has passed
+func (s *service) countFailure(p purpose, recipient string) {
+	if _, err := redis.GetConnection().Increment(p.failureKey(recipient), s.config.VerificationCode.LockoutTime); err != nil {
+		logrus.Errorf("increment %s failure error: %s", p, err)
+	}
+}
+
+// clearFailures clear wrong codes of email or phone number after a code was used
+func (s *service) clearFailures(p purpose, recipient string) {
+	if err := redis.GetConnection().Delete(p.failureKey(recipient)); err != nil {
+		logrus.Errorf("delete %s failure error: %s", p, err)
+	}
+}
+
+// phoneCode pending code sent to phone number
+type phoneCode struct {
+	CodeHash string
+	Attempts int
+}
+
+// storePhoneCode store code of phone number until it expires, previous code is replaced
+func (s *service) storePhoneCode(phoneNumber string, v *phoneCode) error {
+	err := redis.GetConnection().Set(purposePhoneLogin.phoneKey(phoneNumber), v, s.config.PhoneLogin.ExpireTime)
+	if err != nil {
+		logrus.Errorf("set phone code error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findPhoneCode find code of phone number
+func (s *service) findPhoneCode(phoneNumber string) (*phoneCode, error) {
+	v := &phoneCode{}
+	if err := redis.GetConnection().Get(purposePhoneLogin.phoneKey(phoneNumber), v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// countPhoneCodeAttempt count wrong code, code is kept until its original expiry
+func (s *service) countPhoneCodeAttempt(phoneNumber string, v *phoneCode) {
+	conn := redis.GetConnection()
+	key := purposePhoneLogin.phoneKey(phoneNumber)
+	v.Attempts++
+	ttl, err := conn.GetTTL(key)
+	if err != nil || ttl <= time.Second {
+		return
+	}
+
+	if err := conn.Set(key, v, ttl); err != nil {
+		logrus.Errorf("set phone code error: %s", err)
+	}
+}
+
+// deletePhoneCode delete code of phone number
+func (s *service) deletePhoneCode(phoneNumber string) {
+	if err := redis.GetConnection().Delete(purposePhoneLogin.phoneKey(phoneNumber)); err != nil {
+		logrus.Errorf("delete phone code error: %s", err)
+	}
+}
+
+// magicLink pending sign in link sent to email of user
+type magicLink struct {
+	UserID     uint
+	Email      string
+	DeviceHash string
+}
+
+// storeMagicLink store link by hash of its token until it expires,
+// previous link of user is not valid anymore
+func (s *service) storeMagicLink(linkToken string, v *magicLink) error {
+	conn := redis.GetConnection()
+	tokenHash := token.Hash(linkToken)
+	var previous string
+	if err := conn.Get(purposeMagicLink.codeKey(v.UserID), &previous); err == nil {
+		_ = conn.Delete(purposeMagicLink.tokenKey(previous))
+	}
+
+	expireTime := s.config.MagicLink.ExpireTime
+	if err := conn.Set(purposeMagicLink.tokenKey(tokenHash), v, expireTime); err != nil {
+		logrus.Errorf("set magic link error: %s", err)
+		return err
+	}
+
+	if err := conn.Set(purposeMagicLink.codeKey(v.UserID), tokenHash, expireTime); err != nil {
+		logrus.Errorf("set magic link of user error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findMagicLink find link of token
+func (s *service) findMagicLink(linkToken string) (*magicLink, error) {
+	v := &magicLink{}
+	if err := redis.GetConnection().Get(purposeMagicLink.tokenKey(token.Hash(linkToken)), v); err != nil {
+		return nil, err
+	}
+
+	return v, nil
+}
+
+// claimMagicLink get and delete link of token in one transaction,
+// so a link signs in only once even when it is opened concurrently
+func (s *service) claimMagicLink(linkToken string) (*magicLink, error) {
+	conn := redis.GetConnection()
+	tokenHash := token.Hash(linkToken)
+	v := &magicLink{}
+	if err := conn.GetDelete(purposeMagicLink.tokenKey(tokenHash), v); err != nil {
+		return nil, err
+	}
+
+	if err := conn.Delete(purposeMagicLink.codeKey(v.UserID)); err != nil {
+		logrus.Errorf("delete magic link of user error: %s", err)
+	}
+
+	if err := conn.Delete(purposeMagicLink.confirmKey(tokenHash)); err != nil {
+		logrus.Errorf("delete magic link confirmation error: %s", err)
+	}
+
+	return v, nil
+}
+
+// storeMagicLinkConfirmation store hash of confirmation code of link opened on another device,
+// false when one was already sent for the link
+func (s *service) storeMagicLinkConfirmation(linkToken, codeHash string) (bool, error) {
+	key := purposeMagicLink.confirmKey(token.Hash(linkToken))
+	stored, err := redis.GetConnection().SetNX(key, codeHash, s.config.MagicLink.ExpireTime)
+	if err != nil {
+		logrus.Errorf("set magic link confirmation error: %s", err)
+		return false, err
+	}
+
+	return stored, nil
+}
+
+// findMagicLinkConfirmation find hash of confirmation code of link
+func (s *service) findMagicLinkConfirmation(linkToken string) (string, error) {
+	var codeHash string
+	if err := redis.GetConnection().Get(purposeMagicLink.confirmKey(token.Hash(linkToken)), &codeHash); err != nil {
+		return "", err
+	}
+
+	return codeHash, nil
+}
@@ -0,0 +1,301 @@
+// Package oauth is an oauth 2.0 authorization server package
+package oauth
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/handlers"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	Revoke(c *fiber.Ctx) error
+	Introspect(c *fiber.Ctx) error
+	Authorize(c *fiber.Ctx) error
+	ApproveAuthorize(c *fiber.Ctx) error
+	Token(c *fiber.Ctx) error
+	UserInfo(c *fiber.Ctx) error
+	DeviceAuthorization(c *fiber.Ctx) error
+	GetDeviceVerification(c *fiber.Ctx) error
+	VerifyDevice(c *fiber.Ctx) error
+	GetClients(c *fiber.Ctx) error
+	CreateClient(c *fiber.Ctx) error
+	UpdateClient(c *fiber.Ctx) error
+	RegenerateClientSecret(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config       *config.Configs
+	result       *config.ReturnResult
+	service      Service
+	tokenService token.Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:       config.CF,
+		result:       config.RR,
+		service:      NewService(),
+		tokenService: token.NewService(),
+	}
+}
+
+// Revoke revoke token (RFC 7009)
+// @Tags OAuth
+// @Summary Revoke
+// @Description Revoke access token or refresh token, invalid tokens do not cause an error
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param token formData string true "access token or refresh token"
+// @Param token_type_hint formData string false "(access_token, refresh_token)"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /oauth/revoke [post]
+func (ep *endpoint) Revoke(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.tokenService.Revoke, &request.RevokeTokenRequest{})
+}
+
+// Introspect introspect token (RFC 7662)
+// @Tags OAuth
+// @Summary Introspect
+// @Description Check whether token is active, client authenticates with http basic authentication or client_id and client_secret
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param token formData string true "access token or refresh token"
+// @Param token_type_hint formData string false "(access_token, refresh_token)"
+// @Param client_id formData string false "client id"
+// @Param client_secret formData string false "client secret"
+// @Success 200 {object} models.Introspection
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /oauth/introspect [post]
+func (ep *endpoint) Introspect(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.Introspect, &request.IntrospectRequest{})
+}
+
+// Authorize authorization endpoint (RFC 6749 section 4.1.1)
+// @Tags OAuth
+// @Summary Authorize
+// @Description Validate authorization request with pkce and redirect to the consent page, invalid client or redirect uri responds an error without redirect
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param response_type query string true "code"
+// @Param client_id query string true "client id"
+// @Param redirect_uri query string false "registered redirect uri"
+// @Param scope query string false "space separated scopes"
+// @Param state query string false "opaque value returned to the client"
+// @Param code_challenge query string true "pkce code challenge"
+// @Param code_challenge_method query string true "S256"
+// @Param nonce query string false "openid connect nonce returned in id token"
+// @Success 302
+// @Failure 400 {object} models.OAuthError
+// @Router /oauth/authorize [get]
+func (ep *endpoint) Authorize(c *fiber.Ctx) error {
+	return handlers.ResponseRedirect(c, ep.service.Authorize, &request.AuthorizeRequest{})
+}
+
+// ApproveAuthorize approve or deny authorization request
+// @Tags OAuth
+// @Summary Approve authorization request
+// @Description Called by the consent page for logged in user, responds the redirect uri with authorization code or error
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.ApproveAuthorizeRequest true "request body"
+// @Success 200 {object} models.AuthorizationResponse
+// @Failure 400 {object} models.OAuthError
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /oauth/authorize [post]
+func (ep *endpoint) ApproveAuthorize(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.ApproveAuthorize, &request.ApproveAuthorizeRequest{})
+}
+
+// Token token endpoint (RFC 6749 section 3.2)
+// @Tags OAuth
+// @Summary Token
+// @Description Exchange authorization code with pkce code verifier or refresh token for tokens, or issue short-lived service token to confidential client itself. Confidential clients authenticate with http basic authentication or client_id and client_secret.
+// @Description Token exchange issues admins with users:impersonate permission a short-lived, non-renewable token acting as requested_subject, the token has an `act` claim with the admin id
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param grant_type formData string true "(authorization_code, refresh_token, client_credentials, urn:ietf:params:oauth:grant-type:device_code, urn:ietf:params:oauth:grant-type:token-exchange)"
+// @Param code formData string false "authorization code"
+// @Param redirect_uri formData string false "redirect uri of authorization request"
+// @Param code_verifier formData string false "pkce code verifier"
+// @Param refresh_token formData string false "refresh token"
+// @Param scope formData string false "space separated scopes of service token"
+// @Param audience formData string false "audience of service token"
+// @Param device_code formData string false "device code"
+// @Param subject_token formData string false "access token of admin (token exchange)"
+// @Param subject_token_type formData string false "urn:ietf:params:oauth:token-type:access_token"
+// @Param requested_subject formData string false "id of user to impersonate (token exchange)"
+// @Param requested_token_type formData string false "urn:ietf:params:oauth:token-type:access_token"
+// @Param client_id formData string false "client id"
+// @Param client_secret formData string false "client secret"
+// @Success 200 {object} models.RefreshToken
+// @Failure 400 {object} models.OAuthError
+// @Failure 401 {object} models.OAuthError
+// @Router /oauth/token [post]
+func (ep *endpoint) Token(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return handlers.ResponseObject(c, ep.service.Token, &request.TokenRequest{})
+}
+
+// UserInfo userinfo endpoint (OpenID Connect Core 1.0 section 5.3)
+// @Tags OAuth
+// @Summary UserInfo
+// @Description Claims of user by scopes of access token, access token must have openid scope
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {object} models.UserInfo
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /oauth/userinfo [get]
+func (ep *endpoint) UserInfo(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.UserInfo)
+}
+
+// DeviceAuthorization device authorization endpoint (RFC 8628 section 3.1)
+// @Tags OAuth
+// @Summary Device authorization
+// @Description Start device flow, device shows user code and polls token endpoint with device code
+// @Accept x-www-form-urlencoded
+// @Produce json
+// @Param client_id formData string false "client id"
+// @Param client_secret formData string false "client secret"
+// @Param scope formData string false "space separated scopes"
+// @Success 200 {object} models.DeviceAuthorization
+// @Failure 400 {object} models.OAuthError
+// @Failure 401 {object} models.OAuthError
+// @Router /oauth/device_authorization [post]
+func (ep *endpoint) DeviceAuthorization(c *fiber.Ctx) error {
+	c.Set(fiber.HeaderCacheControl, "no-store")
+	return handlers.ResponseObject(c, ep.service.DeviceAuthorization, &request.DeviceAuthorizationRequest{})
+}
+
+// GetDeviceVerification get device authorization of user code
+// @Tags OAuth
+// @Summary Get device verification
+// @Description Client of user code waiting for approval, shown to logged in user before approving
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param user_code query string true "user code"
+// @Success 200 {object} models.DeviceVerification
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /oauth/device [get]
+func (ep *endpoint) GetDeviceVerification(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.GetDeviceVerification, &request.DeviceVerificationRequest{})
+}
+
+// VerifyDevice approve or deny device
+// @Tags OAuth
+// @Summary Verify device
+// @Description Approve or deny device authorization of user code with logged in session
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.DeviceVerificationRequest true "request body"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /oauth/device [post]
+func (ep *endpoint) VerifyDevice(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.VerifyDevice, &request.DeviceVerificationRequest{})
+}
+
+// GetClients get oauth clients
+// @Tags Admin
+// @Summary Get oauth clients
+// @Description Get registered oauth clients
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {array} models.Client
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/oauth/clients [get]
+func (ep *endpoint) GetClients(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.GetClients)
+}
+
+// CreateClient create oauth client
+// @Tags Admin
+// @Summary Create oauth client
+// @Description Register oauth client, client secret is only returned once
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.ClientRequest true "request body"
+// @Success 200 {object} models.ClientWithSecret
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/oauth/clients [post]
+func (ep *endpoint) CreateClient(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.CreateClient, &request.ClientRequest{})
+}
+
+// UpdateClient update oauth client
+// @Tags Admin
+// @Summary Update oauth client
+// @Description Update oauth client
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "client id"
+// @Param request body request.ClientRequest true "request body"
+// @Success 200 {object} models.Client
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/oauth/clients/{id} [put]
+func (ep *endpoint) UpdateClient(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.UpdateClient, &request.ClientRequest{})
+}
+
+// RegenerateClientSecret regenerate client secret
+// @Tags Admin
+// @Summary Regenerate client secret
+// @Description Replace client secret of confidential client, new secret is only returned once
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "client id"
+// @Success 200 {object} models.ClientWithSecret
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/oauth/clients/{id}/secret [post]
+func (ep *endpoint) RegenerateClientSecret(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.RegenerateClientSecret, &request.GetOne{})
+}
@@ -0,0 +1,478 @@
+package oauth
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/utils"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/request"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+	"net/url"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/imroc/req"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// userCodeCharacters characters of user code (RFC 8628 section 6.1)
+	userCodeCharacters = "BCDFGHJKLMNPQRSTVWXZ"
+	// userCodeLength length of user code without separator
+	userCodeLength = 8
+	// codeVerifierMinLength code verifier length (RFC 7636 section 4.1)
+	codeVerifierMinLength = 43
+	codeVerifierMaxLength = 128
+)
+
+// hashClientSecret client secrets are random and long, sha-256 is enough
+// and keeps client authentication fast for gateway calls
+func hashClientSecret(secret string) string {
+	sum := sha256.Sum256([]byte(secret))
+	return hex.EncodeToString(sum[:])
+}
+
+// validClientSecret compare client secret with stored hash
+func validClientSecret(client *models.Client, secret string) bool {
+	return subtle.ConstantTimeCompare([]byte(client.ClientSecretHash), []byte(hashClientSecret(secret))) == 1
+}
+
+// clientCredentials read client credentials from http basic authentication
+// or from request body (RFC 6749 section 2.3.1)
+func clientCredentials(c *context.Context, clientID, clientSecret string) (string, string) {
+	auth := c.Get(fiber.HeaderAuthorization)
+	if !strings.HasPrefix(auth, "Basic ") {
+		return clientID, clientSecret
+	}
+
+	b, err := base64.StdEncoding.DecodeString(strings.TrimPrefix(auth, "Basic "))
+	if err != nil {
+		return clientID, clientSecret
+	}
+
+	pair := strings.SplitN(string(b), ":", 2)
+	if len(pair) != 2 {
+		return clientID, clientSecret
+	}
+
+	id, _ := url.QueryUnescape(pair[0])
+	secret, _ := url.QueryUnescape(pair[1])
+	return id, secret
+}
+
+// authenticateClient authenticate confidential client
+func (s *service) authenticateClient(c *context.Context, clientID, clientSecret string) (*models.Client, error) {
+	clientID, clientSecret = clientCredentials(c, clientID, clientSecret)
+	if clientID == "" || clientSecret == "" {
+		return nil, s.result.Internal.Unauthorized
+	}
+
+	client, err := s.clientRepository.FindByClientID(c.GetDatabase(), clientID)
+	if err != nil {
+		logrus.Errorf("find client by clientID=%s error: %s", clientID, err)
+		return nil, s.result.Internal.Unauthorized
+	}
+
+	if !client.IsActive || !validClientSecret(client, clientSecret) {
+		return nil, s.result.Internal.Unauthorized
+	}
+
+	return client, nil
+}
+
+// tokenClient authenticate client using grant type, public clients only send client id
+func (s *service) tokenClient(c *context.Context, clientID, clientSecret, grantType string) (*models.Client, error) {
+	clientID, clientSecret = clientCredentials(c, clientID, clientSecret)
+	if clientID == "" {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidClient, "client authentication failed")
+	}
+
+	client, err := s.clientRepository.FindByClientID(c.GetDatabase(), clientID)
+	if err != nil {
+		logrus.Errorf("find client by clientID=%s error: %s", clientID, err)
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidClient, "client authentication failed")
+	}
+
+	if !client.IsActive || (!client.Public && (clientSecret == "" || !validClientSecret(client, clientSecret))) {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidClient, "client authentication failed")
+	}
+
+	// public clients cannot keep a secret, so they never get tokens of their own
+	if !client.GrantTypes.Contains(grantType) || (client.Public && grantType == models.GrantTypeClientCredentials) {
+		return nil, models.NewOAuthError(models.OAuthErrorUnauthorizedClient, "grant_type is not allowed for this client")
+	}
+
+	return client, nil
+}
+
+// authorizeClient find client and redirect uri of authorization request,
+// these errors are never sent to the redirect uri because it is not verified yet
+func (s *service) authorizeClient(c *context.Context, f *request.AuthorizeRequest) (*models.Client, string, error) {
+	if f.ClientID == "" {
+		return nil, "", models.NewOAuthError(models.OAuthErrorInvalidRequest, "client_id is required")
+	}
+
+	client, err := s.clientRepository.FindByClientID(c.GetDatabase(), f.ClientID)
+	if err != nil {
+		logrus.Errorf("find client by clientID=%s error: %s", f.ClientID, err)
+		return nil, "", models.NewOAuthError(models.OAuthErrorInvalidRequest, "unknown client_id")
+	}
+
+	if !client.IsActive {
+		return nil, "", models.NewOAuthError(models.OAuthErrorInvalidRequest, "unknown client_id")
+	}
+
+	redirectURI := f.RedirectURI
+	if redirectURI == "" && len(client.RedirectURIs) == 1 {
+		redirectURI = client.RedirectURIs[0]
+	}
+
+	// redirect uri must exactly match a registered one, clients registered
+	// before schemes were checked are validated again
+	if !client.RedirectURIs.Contains(redirectURI) || !validRedirectURI(redirectURI, client.RedirectSchemes) {
+		return nil, "", models.NewOAuthError(models.OAuthErrorInvalidRequest, "redirect_uri is not registered for this client")
+	}
+
+	return client, redirectURI, nil
+}
+
+// checkAuthorizeRequest check authorization request of verified client,
+// these errors are sent back to the redirect uri
+func checkAuthorizeRequest(client *models.Client, f *request.AuthorizeRequest) error {
+	if f.ResponseType != models.ResponseTypeCode {
+		return models.NewOAuthError(models.OAuthErrorUnsupportedResponseType, "response_type must be code")
+	}
+
+	if !client.GrantTypes.Contains(models.GrantTypeAuthorizationCode) {
+		return models.NewOAuthError(models.OAuthErrorUnauthorizedClient, "authorization_code is not allowed for this client")
+	}
+
+	if f.CodeChallenge == "" {
+		return models.NewOAuthError(models.OAuthErrorInvalidRequest, "code_challenge is required")
+	}
+
+	if f.CodeChallengeMethod != models.CodeChallengeMethodS256 {
+		return models.NewOAuthError(models.OAuthErrorInvalidRequest, "code_challenge_method must be S256")
+	}
+
+	for _, scope := range strings.Fields(f.Scope) {
+		if !client.Scopes.Contains(scope) {
+			return models.NewOAuthError(models.OAuthErrorInvalidScope, "scope "+scope+" is not allowed for this client")
+		}
+	}
+
+	if hasScope(f.Scope, models.ScopeOpenID) && !token.IDTokenSupported() {
+		return models.NewOAuthError(models.OAuthErrorInvalidScope, "scope openid needs an asymmetric jwt signing key")
+	}
+
+	return nil
+}
+
+// verifyCodeChallenge verify pkce code verifier against S256 code challenge
+func verifyCodeChallenge(codeVerifier, codeChallenge string) bool {
+	if len(codeVerifier) < codeVerifierMinLength || len(codeVerifier) > codeVerifierMaxLength {
+		return false
+	}
+
+	sum := sha256.Sum256([]byte(codeVerifier))
+	challenge := base64.RawURLEncoding.EncodeToString(sum[:])
+	return subtle.ConstantTimeCompare([]byte(challenge), []byte(codeChallenge)) == 1
+}
+
+// validRedirectURI registered redirect uri must be absolute without fragment,
+// https, plain http for loopback redirects of native apps,
+// or a custom scheme registered on the client (RFC 8252 section 7.1)
+func validRedirectURI(redirectURI string, customSchemes []string) bool {
+	u, err := url.Parse(redirectURI)
+	if err != nil || u.Scheme == "" || u.Fragment != "" {
+		return false
+	}
+
+	switch u.Scheme {
+	case "https":
+		return u.Host != ""
+	case "http":
+		host := u.Hostname()
+		return host == "localhost" || host == "127.0.0.1" || host == "::1"
+	}
+
+	for _, scheme := range customSchemes {
+		if validCustomScheme(scheme) && u.Scheme == strings.ToLower(scheme) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// validCustomScheme custom scheme of native app, schemes of the browser
+// that run or embed content can not be registered
+func validCustomScheme(scheme string) bool {
+	u, err := url.Parse(scheme + ":")
+	if err != nil || u.Scheme != strings.ToLower(scheme) {
+		return false
+	}
+
+	switch u.Scheme {
+	case "http", "https", "javascript", "data", "vbscript", "file", "blob", "about", "filesystem":
+		return false
+	}
+
+	return true
+}
+
+// redirectURL add parameters to redirect uri, keeping its own query
+func redirectURL(redirectURI string, params url.Values) string {
+	u, err := url.Parse(redirectURI)
+	if err != nil {
+		return redirectURI
+	}
+
+	query := u.Query()
+	for key, values := range params {
+		for _, value := range values {
+			if value != "" {
+				query.Add(key, value)
+			}
+		}
+	}
+
+	u.RawQuery = query.Encode()
+	return u.String()
+}
+
+// errorRedirectURL redirect uri with oauth error (RFC 6749 section 4.1.2.1)
+func errorRedirectURL(redirectURI, state string, err error) string {
+	oauthErr, ok := err.(models.OAuthError)
+	if !ok {
+		oauthErr = models.NewOAuthError(models.OAuthErrorServerError, "")
+	}
+
+	return redirectURL(redirectURI, url.Values{
+		"error":             {oauthErr.Code},
+		"error_description": {oauthErr.Description},
+		"state":             {state},
+	})
+}
+
+// tokenResponse complete token response of oauth clients (RFC 6749 section 5.1)
+func (s *service) tokenResponse(t *models.RefreshToken, expiresIn time.Duration) *models.RefreshToken {
+	t.AccessToken = t.JWTToken
+	t.TokenType = models.TokenTypeBearer
+	t.ExpiresIn = int64(expiresIn.Seconds())
+	return t
+}
+
+// supportedGrantType grant types of token endpoint
+func supportedGrantType(grantType string) bool {
+	switch grantType {
+	case models.GrantTypeAuthorizationCode, models.GrantTypeRefreshToken, models.GrantTypeClientCredentials, models.GrantTypeDeviceCode,
+		models.GrantTypeTokenExchange:
+		return true
+	}
+
+	return false
+}
+
+// bindClient bind client request, redirect uris and grant types are validated
+func (s *service) bindClient(client *models.Client, f *request.ClientRequest) error {
+	for _, scheme := range f.RedirectSchemes {
+		if !validCustomScheme(scheme) {
+			return s.result.Internal.BadRequest
+		}
+	}
+
+	for _, redirectURI := range f.RedirectURIs {
+		if !validRedirectURI(redirectURI, f.RedirectSchemes) {
+			return s.result.Internal.BadRequest
+		}
+	}
+
+	for _, grantType := range f.GrantTypes {
+		if !supportedGrantType(grantType) || (f.Public && grantType == models.GrantTypeClientCredentials) {
+			return s.result.Internal.BadRequest
+		}
+	}
+
+	client.Name = f.Name
+	client.RedirectURIs = f.RedirectURIs
+	client.RedirectSchemes = f.RedirectSchemes
+	client.GrantTypes = f.GrantTypes
+	client.Scopes = f.Scopes
+	client.Audiences = f.Audiences
+	client.Public = f.Public
+	client.FirstParty = f.FirstParty
+	client.AllowIntrospection = f.AllowIntrospection
+	if f.IsActive != nil {
+		client.IsActive = *f.IsActive
+	}
+
+	return nil
+}
+
+// generateClientSecret set new client secret hash and return the plain secret,
+// public clients get no secret
+func (s *service) generateClientSecret(client *models.Client) (string, error) {
+	if client.Public {
+		return "", nil
+	}
+
+	secret, err := utils.RandomToken(clientSecretSize)
+	if err != nil {
+		logrus.Errorf("generate client secret error: %s", err)
+		return "", err
+	}
+
+	client.ClientSecretHash = hashClientSecret(secret)
+	return secret, nil
+}
+
+// hasScope space separated scopes contain scope
+func hasScope(scopes, scope string) bool {
+	for _, s := range strings.Fields(scopes) {
+		if s == scope {
+			return true
+		}
+	}
+
+	return false
+}
+
+// userInfo standard claims of user for granted scopes,
+// name and picture come from the profile in user service
+func (s *service) userInfo(c *context.Context, user *models.User, scope string) (*models.UserInfo, error) {
+	info := &models.UserInfo{Sub: fmt.Sprintf("%d", user.ID)}
+	if hasScope(scope, models.ScopeEmail) {
+		verified := user.EmailVerified()
+		info.Email = user.Email
+		info.EmailVerified = &verified
+	}
+
+	if hasScope(scope, models.ScopePhone) {
+		info.PhoneNumber = user.PhoneNumber
+	}
+
+	if hasScope(scope, models.ScopeProfile) {
+		profile, err := s.getUserProfile(c, user.ID)
+		if err != nil {
+			return nil, err
+		}
+
+		info.Name = strings.TrimSpace(profile.FirstName + " " + profile.LastName)
+		info.Picture = profile.ImageURL
+	}
+
+	return info, nil
+}
+
+// getUserProfile get profile of user from user service
+func (s *service) getUserProfile(c *context.Context, userID uint) (*models.Profile, error) {
+	header := req.Header{
+		"accept-language": c.GetLanguage(),
+	}
+
+	profile := &models.Profile{}
+	url := fmt.Sprintf("%s%s/%d", s.config.User.URL, s.config.User.Path.Profile, userID)
+	err := s.clientService.GetRequestAsService(c, s.config.User.Audience, s.config.User.Scope, url, header, nil, profile)
+	if err != nil {
+		logrus.Errorf("get profile of userID=%d error: %s", userID, err)
+		return nil, err
+	}
+
+	return profile, nil
+}
+
+// issueUserTokens issue tokens of user to client with login type and authentication of the session granting them,
+// id token is issued too when openid scope is granted
+func (s *service) issueUserTokens(c *context.Context, client *models.Client, user *models.User, sessionID, scope, nonce string, authTime time.Time) (*models.RefreshToken, error) {
+	grant := token.Grant{ClientID: client.ClientID, Scope: scope, AuthTime: authTime}
+	if session, err := s.tokenService.Session(c, user.ID, sessionID); err == nil {
+		grant.LoginType = session.LoginType
+		grant.AuthMethods = session.AuthMethods
+	}
+
+	t, err := s.tokenService.Create(c, user, grant)
+	if err != nil {
+		return nil, err
+	}
+
+	if hasScope(scope, models.ScopeOpenID) {
+		info, err := s.userInfo(c, user, scope)
+		if err != nil {
+			return nil, err
+		}
+
+		t.IDToken, err = s.tokenService.CreateIDToken(client.ClientID, nonce, authTime, info)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return t, nil
+}
+
+// generateUserCode random user code without vowels or look-alike characters,
+// a code already in use is generated again
+func (s *service) generateUserCode() (string, error) {
+	for {
+		b := make([]byte, userCodeLength)
+		for i := range b {
+			n, err := rand.Int(rand.Reader, big.NewInt(int64(len(userCodeCharacters))))
+			if err != nil {
+				logrus.Errorf("generate user code error: %s", err)
+				return "", err
+			}
+
+			b[i] = userCodeCharacters[n.Int64()]
+		}
+
+		if _, err := s.findDeviceCodeHash(string(b)); err != nil {
+			return string(b), nil
+		}
+	}
+}
+
+// normalizeUserCode user code typed by user, case and separators are ignored
+func normalizeUserCode(userCode string) string {
+	return strings.Map(func(r rune) rune {
+		r = unicode.ToUpper(r)
+		if !strings.ContainsRune(userCodeCharacters, r) {
+			return -1
+		}
+
+		return r
+	}, userCode)
+}
+
+// formatUserCode user code shown to user, e.g. WDJB-MJHT
+func formatUserCode(userCode string) string {
+	if len(userCode) != userCodeLength {
+		return userCode
+	}
+
+	return userCode[:userCodeLength/2] + "-" + userCode[userCodeLength/2:]
+}
+
+// pendingDeviceAuthorization find device authorization of user code waiting for user,
+// returns hash of device code with it
+func (s *service) pendingDeviceAuthorization(userCode string) (string, *deviceAuthorization, error) {
+	deviceCodeHash, err := s.findDeviceCodeHash(normalizeUserCode(userCode))
+	if err != nil {
+		return "", nil, s.result.InvalidCodeOrExpired
+	}
+
+	d, err := s.findDeviceAuthorization(deviceCodeHash)
+	if err != nil || d.Status != deviceStatusPending || time.Now().After(d.ExpiresAt) {
+		return "", nil, s.result.InvalidCodeOrExpired
+	}
+
+	return deviceCodeHash, d, nil
+}
@@ -0,0 +1,86 @@
+package oauth
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestVerifyCodeChallenge(t *testing.T) {
+	// RFC 7636 appendix B
+	verifier := "dBjftJeZ4CVP-mB92K27uhbUJU1p1r_wW1gFWFOEjXk"
+	challenge := "E9Melhoa2OwvFrEMTJguCHaoeK1t8URWbuGJSstw-cM"
+
+	tests := []struct {
+		name          string
+		codeVerifier  string
+		codeChallenge string
+		want          bool
+	}{
+		{name: "matching verifier", codeVerifier: verifier, codeChallenge: challenge, want: true},
+		{name: "other verifier", codeVerifier: strings.Repeat("a", 43), codeChallenge: challenge, want: false},
+		{name: "plain challenge", codeVerifier: verifier, codeChallenge: verifier, want: false},
+		{name: "empty challenge", codeVerifier: verifier, codeChallenge: "", want: false},
+		{name: "verifier too short", codeVerifier: strings.Repeat("a", codeVerifierMinLength-1), codeChallenge: challenge, want: false},
+		{name: "verifier too long", codeVerifier: strings.Repeat("a", codeVerifierMaxLength+1), codeChallenge: challenge, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, verifyCodeChallenge(tt.codeVerifier, tt.codeChallenge))
+		})
+	}
+}
+
+func TestNormalizeUserCode(t *testing.T) {
+	tests := []struct {
+		name     string
+		userCode string
+		want     string
+	}{
+		{name: "normalized", userCode: "WDJBMJHT", want: "WDJBMJHT"},
+		{name: "formatted", userCode: "WDJB-MJHT", want: "WDJBMJHT"},
+		{name: "lower case", userCode: "wdjb-mjht", want: "WDJBMJHT"},
+		{name: "spaces", userCode: " wdjb mjht ", want: "WDJBMJHT"},
+		{name: "vowels and digits dropped", userCode: "WDJB-0AEI-MJHT", want: "WDJBMJHT"},
+		{name: "empty", userCode: "", want: ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, normalizeUserCode(tt.userCode))
+		})
+	}
+}
+
+func TestValidRedirectURI(t *testing.T) {
+	tests := []struct {
+		name          string
+		redirectURI   string
+		customSchemes []string
+		want          bool
+	}{
+		{name: "https", redirectURI: "https://shop.example.com/callback", want: true},
+		{name: "https without host", redirectURI: "https:/callback", want: false},
+		{name: "fragment", redirectURI: "https://shop.example.com/callback#token", want: false},
+		{name: "relative", redirectURI: "/callback", want: false},
+		{name: "http localhost", redirectURI: "http://localhost:8080/callback", want: true},
+		{name: "http ipv4 loopback", redirectURI: "http://127.0.0.1:51004/callback", want: true},
+		{name: "http ipv6 loopback", redirectURI: "http://[::1]:51004/callback", want: true},
+		{name: "http remote", redirectURI: "http://shop.example.com/callback", want: false},
+		{name: "registered custom scheme", redirectURI: "com.example.shop:/callback", customSchemes: []string{"com.example.shop"}, want: true},
+		{name: "unregistered custom scheme", redirectURI: "com.example.shop:/callback", want: false},
+		{name: "other custom scheme", redirectURI: "com.example.other:/callback", customSchemes: []string{"com.example.shop"}, want: false},
+		{name: "javascript", redirectURI: "javascript:alert(1)", want: false},
+		{name: "registered javascript", redirectURI: "javascript:alert(1)", customSchemes: []string{"javascript"}, want: false},
+		{name: "data", redirectURI: "data:text/html,<script>alert(1)</script>", customSchemes: []string{"data"}, want: false},
+		{name: "file", redirectURI: "file:///etc/passwd", customSchemes: []string{"file"}, want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validRedirectURI(tt.redirectURI, tt.customSchemes))
+		})
+	}
+}
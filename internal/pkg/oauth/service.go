@@ -0,0 +1,630 @@
+package oauth
+
+import (
+	"ecommerce-authen/internal/core/audit"
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/unique"
+	"ecommerce-authen/internal/core/utils"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/client"
+	"ecommerce-authen/internal/pkg/rbac"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/repositories"
+	"ecommerce-authen/internal/request"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// clientSecretSize bytes of random client secret
+	clientSecretSize = 32
+	// authorizationCodeSize bytes of random authorization code
+	authorizationCodeSize = 32
+	// deviceCodeSize bytes of random device code
+	deviceCodeSize = 32
+	// slowDownInterval added to polling interval of device polling too fast (RFC 8628 section 3.5)
+	slowDownInterval = 5 * time.Second
+)
+
+// Service service interface
+type Service interface {
+	Introspect(c *context.Context, request *request.IntrospectRequest) (*models.Introspection, error)
+	Authorize(c *context.Context, request *request.AuthorizeRequest) (string, error)
+	ApproveAuthorize(c *context.Context, request *request.ApproveAuthorizeRequest) (*models.AuthorizationResponse, error)
+	Token(c *context.Context, request *request.TokenRequest) (*models.RefreshToken, error)
+	UserInfo(c *context.Context) (*models.UserInfo, error)
+	DeviceAuthorization(c *context.Context, request *request.DeviceAuthorizationRequest) (*models.DeviceAuthorization, error)
+	GetDeviceVerification(c *context.Context, request *request.DeviceVerificationRequest) (*models.DeviceVerification, error)
+	VerifyDevice(c *context.Context, request *request.DeviceVerificationRequest) error
+	GetClients(c *context.Context) ([]*models.Client, error)
+	CreateClient(c *context.Context, request *request.ClientRequest) (*models.ClientWithSecret, error)
+	UpdateClient(c *context.Context, request *request.ClientRequest) (*models.Client, error)
+	RegenerateClientSecret(c *context.Context, request *request.GetOne) (*models.ClientWithSecret, error)
+}
+
+type service struct {
+	config           *config.Configs
+	result           *config.ReturnResult
+	clientRepository repositories.ClientRepository
+	userRepository   repositories.UserRepository
+	tokenService     token.Service
+	clientService    client.Service
+	rbacService      rbac.Service
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config:           config.CF,
+		result:           config.RR,
+		clientRepository: repositories.ClientNewRepository(),
+		userRepository:   repositories.UserNewRepository(),
+		tokenService:     token.NewService(),
+		clientService:    client.NewService(),
+		rbacService:      rbac.NewService(),
+	}
+}
+
+// Introspect introspect token for internal services and gateway,
+// only clients allowed to introspect can call it
+func (s *service) Introspect(c *context.Context, request *request.IntrospectRequest) (*models.Introspection, error) {
+	client, err := s.authenticateClient(c, request.ClientID, request.ClientSecret)
+	if err != nil {
+		return nil, err
+	}
+
+	if !client.AllowIntrospection {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	return s.tokenService.Introspect(c, request.Token, request.TokenTypeHint)
+}
+
+// Authorize validate authorization request and send user to the consent page,
+// invalid requests of a verified redirect uri are sent back to the client
+func (s *service) Authorize(c *context.Context, request *request.AuthorizeRequest) (string, error) {
+	client, redirectURI, err := s.authorizeClient(c, request)
+	if err != nil {
+		return "", err
+	}
+
+	if err := checkAuthorizeRequest(client, request); err != nil {
+		return errorRedirectURL(redirectURI, request.State, err), nil
+	}
+
+	return redirectURL(s.config.OAuth.ConsentURL, url.Values{
+		"response_type":         {request.ResponseType},
+		"client_id":             {client.ClientID},
+		"client_name":           {client.Name},
+		"redirect_uri":          {redirectURI},
+		"scope":                 {request.Scope},
+		"state":                 {request.State},
+		"code_challenge":        {request.CodeChallenge},
+		"code_challenge_method": {request.CodeChallengeMethod},
+		"nonce":                 {request.Nonce},
+	}), nil
+}
+
+// ApproveAuthorize issue authorization code for logged in user,
+// first-party clients are approved without asking the user
+func (s *service) ApproveAuthorize(c *context.Context, request *request.ApproveAuthorizeRequest) (*models.AuthorizationResponse, error) {
+	// tokens issued to oauth clients cannot authorize other clients
+	claims := c.GetClaims()
+	if claims.ClientID != "" {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	client, redirectURI, err := s.authorizeClient(c, &request.AuthorizeRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := checkAuthorizeRequest(client, &request.AuthorizeRequest); err != nil {
+		return &models.AuthorizationResponse{RedirectURI: errorRedirectURL(redirectURI, request.State, err)}, nil
+	}
+
+	if !request.Approve && !client.FirstParty {
+		err := models.NewOAuthError(models.OAuthErrorAccessDenied, "user denied the request")
+		return &models.AuthorizationResponse{RedirectURI: errorRedirectURL(redirectURI, request.State, err)}, nil
+	}
+
+	code, err := utils.RandomToken(authorizationCodeSize)
+	if err != nil {
+		logrus.Errorf("generate authorization code error: %s", err)
+		return nil, err
+	}
+
+	ac := &authorizationCode{
+		UserID:        c.GetUserID(),
+		SessionID:     claims.SessionID,
+		ClientID:      client.ClientID,
+		RedirectURI:   redirectURI,
+		Scope:         request.Scope,
+		CodeChallenge: request.CodeChallenge,
+		Nonce:         request.Nonce,
+	}
+
+	// users authenticated when the session consenting the request was created
+	if session, err := s.tokenService.Session(c, ac.UserID, ac.SessionID); err == nil {
+		ac.AuthTime = session.CreatedAt
+	}
+
+	if err := s.storeAuthorizationCode(code, ac); err != nil {
+		return nil, err
+	}
+
+	return &models.AuthorizationResponse{
+		RedirectURI: redirectURL(redirectURI, url.Values{
+			"code":  {code},
+			"state": {request.State},
+		}),
+	}, nil
+}
+
+// Token token endpoint of oauth clients
+func (s *service) Token(c *context.Context, request *request.TokenRequest) (*models.RefreshToken, error) {
+	if !supportedGrantType(request.GrantType) {
+		return nil, models.NewOAuthError(models.OAuthErrorUnsupportedGrantType, "")
+	}
+
+	client, err := s.tokenClient(c, request.ClientID, request.ClientSecret, request.GrantType)
+	if err != nil {
+		return nil, err
+	}
+
+	switch request.GrantType {
+	case models.GrantTypeRefreshToken:
+		if request.RefreshToken == "" {
+			return nil, models.NewOAuthError(models.OAuthErrorInvalidRequest, "refresh_token is required")
+		}
+
+		t, err := s.tokenService.RenewClientToken(c, client.ClientID, request.RefreshToken)
+		if err != nil {
+			return nil, models.NewOAuthError(models.OAuthErrorInvalidGrant, "refresh_token is invalid or expired")
+		}
+
+		return s.tokenResponse(t, s.config.JWT.ExpireTime), nil
+
+	case models.GrantTypeClientCredentials:
+		return s.clientCredentialsToken(c, client, request)
+
+	case models.GrantTypeDeviceCode:
+		return s.deviceCodeToken(c, client, request)
+
+	case models.GrantTypeTokenExchange:
+		return s.impersonationToken(c, client, request)
+	}
+
+	return s.exchangeAuthorizationCode(c, client, request)
+}
+
+// clientCredentialsToken issue service token for one audience (RFC 6749 section 4.4),
+// requested scopes must be allowed for client and all of them are granted when none requested
+func (s *service) clientCredentialsToken(c *context.Context, client *models.Client, request *request.TokenRequest) (*models.RefreshToken, error) {
+	audience := request.Audience
+	if audience == "" && len(client.Audiences) == 1 {
+		audience = client.Audiences[0]
+	}
+
+	if audience == "" || !client.Audiences.Contains(audience) {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidTarget, "audience is not allowed for this client")
+	}
+
+	scopes := strings.Fields(request.Scope)
+	if len(scopes) == 0 {
+		scopes = client.Scopes
+	}
+
+	for _, scope := range scopes {
+		if !client.Scopes.Contains(scope) {
+			return nil, models.NewOAuthError(models.OAuthErrorInvalidScope, "scope "+scope+" is not allowed for this client")
+		}
+	}
+
+	t, err := s.tokenService.CreateServiceToken(c, client.ClientID, audience, strings.Join(scopes, " "))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenResponse(t, s.config.JWT.ServiceTokenExpireTime), nil
+}
+
+// impersonationToken admin exchanges own access token (subject_token) for a short-lived
+// token acting as user (requested_subject) with admin as actor (RFC 8693), admins can not be impersonated
+func (s *service) impersonationToken(c *context.Context, client *models.Client, request *request.TokenRequest) (*models.RefreshToken, error) {
+	if request.SubjectToken == "" || request.SubjectTokenType != models.TokenTypeAccessToken || request.RequestedSubject == "" {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidRequest, "subject_token of access token type and requested_subject are required")
+	}
+
+	if request.RequestedTokenType != "" && request.RequestedTokenType != models.TokenTypeAccessToken {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidRequest, "requested_token_type is not supported")
+	}
+
+	subject, err := token.VerifyAccessToken(request.SubjectToken, c.GetSource())
+	if err != nil {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidGrant, "subject_token is invalid or expired")
+	}
+
+	claims := subject.Claims.(*context.Claims)
+	actorID, _ := strconv.ParseUint(claims.Subject, 10, 64)
+	if claims.IsImpersonation() || (claims.ClientID != "" && !claims.HasScope(models.PermissionUsersImpersonate)) {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidGrant, "subject_token is not allowed to impersonate")
+	}
+
+	permissions, err := s.rbacService.UserPermissions(c, uint(actorID))
+	if err != nil {
+		return nil, err
+	}
+
+	if !models.HasPermission(permissions, models.PermissionUsersImpersonate) {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidGrant, "subject_token is not allowed to impersonate")
+	}
+
+	userID, _ := strconv.ParseUint(request.RequestedSubject, 10, 64)
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), uint(userID), user); err != nil {
+		logrus.Errorf("find user id=%s error: %s", request.RequestedSubject, err)
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidTarget, "requested_subject is not found")
+	}
+
+	if user.ID == uint(actorID) || user.Role == models.RoleAdmin || user.Deactivated() {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidTarget, "requested_subject can not be impersonated")
+	}
+
+	// staff are refused by their effective permissions, not only by the legacy admin role
+	targetPermissions, err := s.rbacService.UserPermissions(c, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if models.HasPrivilegedPermission(targetPermissions) {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidTarget, "requested_subject can not be impersonated")
+	}
+
+	for _, scope := range strings.Fields(request.Scope) {
+		if !client.Scopes.Contains(scope) {
+			return nil, models.NewOAuthError(models.OAuthErrorInvalidScope, "scope "+scope+" is not allowed for this client")
+		}
+	}
+
+	t, err := s.tokenService.Create(c, user, token.Grant{ClientID: client.ClientID, Scope: request.Scope, ActorID: uint(actorID)})
+	if err != nil {
+		return nil, err
+	}
+
+	audit.Security(audit.EventImpersonationTokenIssued, logrus.Fields{
+		"admin_id":   actorID,
+		"user_id":    user.ID,
+		"client_id":  client.ClientID,
+		"session_id": t.SessionID,
+		"scope":      request.Scope,
+		"ip":         c.IP(),
+		"user_agent": c.Get(fiber.HeaderUserAgent),
+	})
+
+	t = s.tokenResponse(t, s.config.JWT.ImpersonateExpireTime)
+	t.IssuedTokenType = models.TokenTypeAccessToken
+	return t, nil
+}
+
+// exchangeAuthorizationCode exchange authorization code for tokens,
+// a code presented twice revokes the tokens issued with it (RFC 6749 section 4.1.2)
+func (s *service) exchangeAuthorizationCode(c *context.Context, client *models.Client, request *request.TokenRequest) (*models.RefreshToken, error) {
+	invalidGrant := models.NewOAuthError(models.OAuthErrorInvalidGrant, "authorization code is invalid or expired")
+	if request.Code == "" {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidRequest, "code is required")
+	}
+
+	ac, err := s.findAuthorizationCode(request.Code)
+	if err != nil || ac.ClientID != client.ClientID {
+		return nil, invalidGrant
+	}
+
+	claimed, err := s.claimAuthorizationCode(request.Code)
+	if err != nil {
+		return nil, err
+	}
+
+	if !claimed {
+		s.deleteAuthorizationCode(request.Code)
+		if sessionID, err := s.findIssuedSessionID(request.Code); err == nil && sessionID != "" {
+			_ = s.tokenService.RevokeSession(c, ac.UserID, sessionID)
+		}
+
+		return nil, invalidGrant
+	}
+
+	if request.RedirectURI != ac.RedirectURI || !verifyCodeChallenge(request.CodeVerifier, ac.CodeChallenge) {
+		return nil, invalidGrant
+	}
+
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), ac.UserID, user); err != nil {
+		logrus.Errorf("find user id=%d error: %s", ac.UserID, err)
+		return nil, invalidGrant
+	}
+
+	if user.Deactivated() {
+		return nil, invalidGrant
+	}
+
+	t, err := s.issueUserTokens(c, client, user, ac.SessionID, ac.Scope, ac.Nonce, ac.AuthTime)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.storeIssuedSessionID(request.Code, t.SessionID); err != nil {
+		return nil, err
+	}
+
+	return s.tokenResponse(t, s.config.JWT.ExpireTime), nil
+}
+
+// UserInfo claims of user granted to access token (OpenID Connect Core 1.0 section 5.3)
+func (s *service) UserInfo(c *context.Context) (*models.UserInfo, error) {
+	claims := c.GetClaims()
+	if !hasScope(claims.Scope, models.ScopeOpenID) {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), c.GetUserID(), user); err != nil {
+		logrus.Errorf("find user id=%d error: %s", c.GetUserID(), err)
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	return s.userInfo(c, user, claims.Scope)
+}
+
+// DeviceAuthorization start device authorization (RFC 8628 section 3.1),
+// device shows user code and polls token endpoint with device code
+func (s *service) DeviceAuthorization(c *context.Context, request *request.DeviceAuthorizationRequest) (*models.DeviceAuthorization, error) {
+	client, err := s.tokenClient(c, request.ClientID, request.ClientSecret, models.GrantTypeDeviceCode)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, scope := range strings.Fields(request.Scope) {
+		if !client.Scopes.Contains(scope) {
+			return nil, models.NewOAuthError(models.OAuthErrorInvalidScope, "scope "+scope+" is not allowed for this client")
+		}
+	}
+
+	deviceCode, err := utils.RandomToken(deviceCodeSize)
+	if err != nil {
+		logrus.Errorf("generate device code error: %s", err)
+		return nil, err
+	}
+
+	userCode, err := s.generateUserCode()
+	if err != nil {
+		return nil, err
+	}
+
+	d := &deviceAuthorization{
+		ClientID:  client.ClientID,
+		Scope:     request.Scope,
+		UserCode:  userCode,
+		Status:    deviceStatusPending,
+		Interval:  s.config.OAuth.DeviceCodeInterval,
+		ExpiresAt: time.Now().Add(s.config.OAuth.DeviceCodeExpireTime),
+	}
+
+	deviceCodeHash := token.Hash(deviceCode)
+	if err := s.storeDeviceAuthorization(deviceCodeHash, d); err != nil {
+		return nil, err
+	}
+
+	if err := s.storeUserCode(userCode, deviceCodeHash, d.ExpiresAt); err != nil {
+		return nil, err
+	}
+
+	return &models.DeviceAuthorization{
+		DeviceCode:              deviceCode,
+		UserCode:                formatUserCode(userCode),
+		VerificationURI:         s.config.OAuth.DeviceVerificationURL,
+		VerificationURIComplete: redirectURL(s.config.OAuth.DeviceVerificationURL, url.Values{"user_code": {formatUserCode(userCode)}}),
+		ExpiresIn:               int64(s.config.OAuth.DeviceCodeExpireTime.Seconds()),
+		Interval:                int64(d.Interval.Seconds()),
+	}, nil
+}
+
+// GetDeviceVerification get pending device authorization of user code for the approval page
+func (s *service) GetDeviceVerification(c *context.Context, request *request.DeviceVerificationRequest) (*models.DeviceVerification, error) {
+	_, d, err := s.pendingDeviceAuthorization(request.UserCode)
+	if err != nil {
+		return nil, err
+	}
+
+	client, err := s.clientRepository.FindByClientID(c.GetDatabase(), d.ClientID)
+	if err != nil {
+		logrus.Errorf("find client by clientID=%s error: %s", d.ClientID, err)
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	return &models.DeviceVerification{
+		UserCode:   formatUserCode(d.UserCode),
+		ClientID:   client.ClientID,
+		ClientName: client.Name,
+		Scope:      d.Scope,
+	}, nil
+}
+
+// VerifyDevice approve or deny device authorization from logged in session
+func (s *service) VerifyDevice(c *context.Context, request *request.DeviceVerificationRequest) error {
+	// tokens issued to oauth clients cannot authorize other clients
+	claims := c.GetClaims()
+	if claims.ClientID != "" {
+		return s.result.InvalidPermissionRole
+	}
+
+	deviceCodeHash, d, err := s.pendingDeviceAuthorization(request.UserCode)
+	if err != nil {
+		return err
+	}
+
+	d.Status = deviceStatusDenied
+	if request.Approve {
+		d.Status = deviceStatusApproved
+		d.UserID = c.GetUserID()
+		d.SessionID = claims.SessionID
+		if session, err := s.tokenService.Session(c, d.UserID, d.SessionID); err == nil {
+			d.AuthTime = session.CreatedAt
+		}
+	}
+
+	return s.storeDeviceAuthorization(deviceCodeHash, d)
+}
+
+// deviceCodeToken device polls for tokens (RFC 8628 section 3.4),
+// polling faster than the interval slows the device down
+func (s *service) deviceCodeToken(c *context.Context, client *models.Client, request *request.TokenRequest) (*models.RefreshToken, error) {
+	if request.DeviceCode == "" {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidRequest, "device_code is required")
+	}
+
+	deviceCodeHash := token.Hash(request.DeviceCode)
+	d, err := s.findDeviceAuthorization(deviceCodeHash)
+	if err != nil || time.Now().After(d.ExpiresAt) {
+		return nil, models.NewOAuthError(models.OAuthErrorExpiredToken, "device_code is expired")
+	}
+
+	if d.ClientID != client.ClientID {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidGrant, "device_code was issued to another client")
+	}
+
+	switch d.Status {
+	case deviceStatusDenied:
+		s.deleteDeviceAuthorization(deviceCodeHash, d.UserCode)
+		return nil, models.NewOAuthError(models.OAuthErrorAccessDenied, "user denied the request")
+
+	case deviceStatusPending:
+		now := time.Now()
+		slowDown := now.Sub(d.LastPolledAt) < d.Interval
+		if slowDown {
+			d.Interval += slowDownInterval
+		}
+
+		d.LastPolledAt = now
+		if err := s.storeDeviceAuthorization(deviceCodeHash, d); err != nil {
+			return nil, err
+		}
+
+		if slowDown {
+			return nil, models.NewOAuthError(models.OAuthErrorSlowDown, "")
+		}
+
+		return nil, models.NewOAuthError(models.OAuthErrorAuthorizationPending, "")
+	}
+
+	// approved device code is claimed atomically so concurrent polls get tokens only once
+	d, err = s.claimDeviceAuthorization(deviceCodeHash)
+	if err != nil || d.Status != deviceStatusApproved {
+		return nil, models.NewOAuthError(models.OAuthErrorExpiredToken, "device_code is expired")
+	}
+
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), d.UserID, user); err != nil {
+		logrus.Errorf("find user id=%d error: %s", d.UserID, err)
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidGrant, "user not found")
+	}
+
+	if user.Deactivated() {
+		return nil, models.NewOAuthError(models.OAuthErrorInvalidGrant, "user is deactivated")
+	}
+
+	t, err := s.issueUserTokens(c, client, user, d.SessionID, d.Scope, "", d.AuthTime)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.tokenResponse(t, s.config.JWT.ExpireTime), nil
+}
+
+// GetClients get oauth clients
+func (s *service) GetClients(c *context.Context) ([]*models.Client, error) {
+	clients := []*models.Client{}
+	if err := s.clientRepository.FindAll(c.GetDatabase(), &clients); err != nil {
+		logrus.Errorf("find clients error: %s", err)
+		return nil, err
+	}
+
+	return clients, nil
+}
+
+// CreateClient register oauth client, active by default, public clients have no secret
+func (s *service) CreateClient(c *context.Context, request *request.ClientRequest) (*models.ClientWithSecret, error) {
+	client := &models.Client{ClientID: unique.NewXid(), IsActive: true}
+	if err := s.bindClient(client, request); err != nil {
+		return nil, err
+	}
+
+	secret, err := s.generateClientSecret(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.clientRepository.Create(c.GetDatabase(), client); err != nil {
+		logrus.Errorf("create client error: %s", err)
+		return nil, err
+	}
+
+	return &models.ClientWithSecret{Client: client, ClientSecret: secret}, nil
+}
+
+// UpdateClient update oauth client
+func (s *service) UpdateClient(c *context.Context, request *request.ClientRequest) (*models.Client, error) {
+	db := c.GetDatabase()
+	client := &models.Client{}
+	if err := s.clientRepository.FindOneObjectByIDUInt(db, request.ID, client); err != nil {
+		logrus.Errorf("find client id=%d error: %s", request.ID, err)
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	if err := s.bindClient(client, request); err != nil {
+		return nil, err
+	}
+
+	if client.Public {
+		client.ClientSecretHash = ""
+	}
+
+	if err := s.clientRepository.Update(db, client); err != nil {
+		logrus.Errorf("update client error: %s", err)
+		return nil, err
+	}
+
+	return client, nil
+}
+
+// RegenerateClientSecret replace client secret, the old secret stops working immediately
+func (s *service) RegenerateClientSecret(c *context.Context, request *request.GetOne) (*models.ClientWithSecret, error) {
+	db := c.GetDatabase()
+	client := &models.Client{}
+	if err := s.clientRepository.FindOneObjectByIDUInt(db, request.ID, client); err != nil {
+		logrus.Errorf("find client id=%d error: %s", request.ID, err)
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	if client.Public {
+		return nil, s.result.Internal.BadRequest
+	}
+
+	secret, err := s.generateClientSecret(client)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.clientRepository.Update(db, client); err != nil {
+		logrus.Errorf("update client error: %s", err)
+		return nil, err
+	}
+
+	return &models.ClientWithSecret{Client: client, ClientSecret: secret}, nil
+}
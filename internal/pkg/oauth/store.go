@@ -0,0 +1,182 @@
+package oauth
+
+import (
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/pkg/token"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	authorizationCodeKeyPrefix     = "authorization_code:"
+	usedAuthorizationCodeKeyPrefix = "authorization_code_used:"
+	deviceCodeKeyPrefix            = "device_code:"
+	userCodeKeyPrefix              = "user_code:"
+)
+
+// deviceStatus status of device authorization
+type deviceStatus string
+
+const (
+	deviceStatusPending  deviceStatus = "pending"
+	deviceStatusApproved deviceStatus = "approved"
+	deviceStatusDenied   deviceStatus = "denied"
+)
+
+// authorizationCode authorization granted by user, exchanged once for tokens
+type authorizationCode struct {
+	UserID        uint
+	SessionID     string
+	ClientID      string
+	RedirectURI   string
+	Scope         string
+	CodeChallenge string
+	Nonce         string
+	AuthTime      time.Time
+}
+
+// storeAuthorizationCode store authorization code by its hash until it expires
+func (s *service) storeAuthorizationCode(code string, ac *authorizationCode) error {
+	err := redis.GetConnection().Set(authorizationCodeKeyPrefix+token.Hash(code), ac, s.config.OAuth.AuthorizationCodeExpireTime)
+	if err != nil {
+		logrus.Errorf("set authorization code error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findAuthorizationCode find authorization code
+func (s *service) findAuthorizationCode(code string) (*authorizationCode, error) {
+	ac := &authorizationCode{}
+	if err := redis.GetConnection().Get(authorizationCodeKeyPrefix+token.Hash(code), ac); err != nil {
+		return nil, err
+	}
+
+	return ac, nil
+}
+
+// claimAuthorizationCode mark authorization code used in one command (SET NX),
+// so of concurrent exchanges of the same code only one gets true
+func (s *service) claimAuthorizationCode(code string) (bool, error) {
+	claimed, err := redis.GetConnection().SetNX(usedAuthorizationCodeKeyPrefix+token.Hash(code), "", s.config.OAuth.AuthorizationCodeExpireTime)
+	if err != nil {
+		logrus.Errorf("claim authorization code error: %s", err)
+		return false, err
+	}
+
+	return claimed, nil
+}
+
+// storeIssuedSessionID store session issued with used authorization code, revoked when the code is presented again
+func (s *service) storeIssuedSessionID(code, sessionID string) error {
+	err := redis.GetConnection().Set(usedAuthorizationCodeKeyPrefix+token.Hash(code), sessionID, s.config.OAuth.AuthorizationCodeExpireTime)
+	if err != nil {
+		logrus.Errorf("set session of used authorization code error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findIssuedSessionID find session issued with used authorization code
+func (s *service) findIssuedSessionID(code string) (string, error) {
+	var sessionID string
+	if err := redis.GetConnection().Get(usedAuthorizationCodeKeyPrefix+token.Hash(code), &sessionID); err != nil {
+		return "", err
+	}
+
+	return sessionID, nil
+}
+
+// deleteAuthorizationCode delete authorization code
+func (s *service) deleteAuthorizationCode(code string) {
+	if err := redis.GetConnection().Delete(authorizationCodeKeyPrefix + token.Hash(code)); err != nil {
+		logrus.Errorf("delete authorization code error: %s", err)
+	}
+}
+
+// deviceAuthorization device authorization waiting for user, polled by device with device code
+type deviceAuthorization struct {
+	ClientID     string
+	Scope        string
+	UserCode     string
+	Status       deviceStatus
+	UserID       uint
+	SessionID    string
+	AuthTime     time.Time
+	Interval     time.Duration
+	LastPolledAt time.Time
+	ExpiresAt    time.Time
+}
+
+// storeDeviceAuthorization store device authorization by hash of device code until it expires
+func (s *service) storeDeviceAuthorization(deviceCodeHash string, d *deviceAuthorization) error {
+	err := redis.GetConnection().Set(deviceCodeKeyPrefix+deviceCodeHash, d, time.Until(d.ExpiresAt))
+	if err != nil {
+		logrus.Errorf("set device authorization error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findDeviceAuthorization find device authorization by hash of device code
+func (s *service) findDeviceAuthorization(deviceCodeHash string) (*deviceAuthorization, error) {
+	d := &deviceAuthorization{}
+	if err := redis.GetConnection().Get(deviceCodeKeyPrefix+deviceCodeHash, d); err != nil {
+		return nil, err
+	}
+
+	return d, nil
+}
+
+// claimDeviceAuthorization get and delete device authorization and its user code in one transaction,
+// so only one of concurrent callers gets it
+func (s *service) claimDeviceAuthorization(deviceCodeHash string) (*deviceAuthorization, error) {
+	conn := redis.GetConnection()
+	d := &deviceAuthorization{}
+	if err := conn.GetDelete(deviceCodeKeyPrefix+deviceCodeHash, d); err != nil {
+		return nil, err
+	}
+
+	if err := conn.Delete(userCodeKeyPrefix + token.Hash(d.UserCode)); err != nil {
+		logrus.Errorf("delete user code error: %s", err)
+	}
+
+	return d, nil
+}
+
+// storeUserCode store hash of device code by hash of user code
+func (s *service) storeUserCode(userCode, deviceCodeHash string, expiresAt time.Time) error {
+	err := redis.GetConnection().Set(userCodeKeyPrefix+token.Hash(userCode), deviceCodeHash, time.Until(expiresAt))
+	if err != nil {
+		logrus.Errorf("set user code error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findDeviceCodeHash find hash of device code by user code
+func (s *service) findDeviceCodeHash(userCode string) (string, error) {
+	var deviceCodeHash string
+	if err := redis.GetConnection().Get(userCodeKeyPrefix+token.Hash(userCode), &deviceCodeHash); err != nil {
+		return "", err
+	}
+
+	return deviceCodeHash, nil
+}
+
+// deleteDeviceAuthorization delete device authorization and its user code
+func (s *service) deleteDeviceAuthorization(deviceCodeHash, userCode string) {
+	conn := redis.GetConnection()
+	if err := conn.Delete(userCodeKeyPrefix + token.Hash(userCode)); err != nil {
+		logrus.Errorf("delete user code error: %s", err)
+	}
+
+	if err := conn.Delete(deviceCodeKeyPrefix + deviceCodeHash); err != nil {
+		logrus.Errorf("delete device authorization error: %s", err)
+	}
+}
@@ -0,0 +1,147 @@
+package passkey
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/protocol/webauthncbor"
+	"github.com/go-webauthn/webauthn/protocol/webauthncose"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	flagUserPresent  byte = 0x01
+	flagUserVerified byte = 0x04
+	flagAttestedData byte = 0x40
+)
+
+// softwareAuthenticator authenticator creating and using one es256 credential
+// with "none" attestation, used to run ceremonies without a browser
+type softwareAuthenticator struct {
+	origin       string
+	key          *ecdsa.PrivateKey
+	credentialID []byte
+	userHandle   []byte
+	signCount    uint32
+	userVerified bool
+}
+
+// newSoftwareAuthenticator new authenticator of origin
+func newSoftwareAuthenticator(t *testing.T, origin string) *softwareAuthenticator {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	credentialID := make([]byte, 16)
+	_, err = rand.Read(credentialID)
+	require.NoError(t, err)
+
+	return &softwareAuthenticator{origin: origin, key: key, credentialID: credentialID, userVerified: true}
+}
+
+// flags authenticator data flags of ceremony
+func (a *softwareAuthenticator) flags() byte {
+	flags := flagUserPresent
+	if a.userVerified {
+		flags |= flagUserVerified
+	}
+
+	return flags
+}
+
+// clientData client data json of ceremony type and challenge
+func (a *softwareAuthenticator) clientData(t *testing.T, ceremony string, challenge protocol.URLEncodedBase64) []byte {
+	b, err := json.Marshal(map[string]string{
+		"type":      ceremony,
+		"challenge": challenge.String(),
+		"origin":    a.origin,
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+// authenticatorData rp id hash, flags and sign count followed by extra data
+func (a *softwareAuthenticator) authenticatorData(rpID string, flags byte, extra []byte) []byte {
+	rpIDHash := sha256.Sum256([]byte(rpID))
+	data := append([]byte{}, rpIDHash[:]...)
+	data = append(data, flags, 0, 0, 0, 0)
+	binary.BigEndian.PutUint32(data[len(data)-4:], a.signCount)
+	return append(data, extra...)
+}
+
+// create answer navigator.credentials.create options, returns body of credential
+func (a *softwareAuthenticator) create(t *testing.T, options *protocol.CredentialCreation) []byte {
+	a.userHandle = options.Response.User.ID.(protocol.URLEncodedBase64)
+	publicKey, err := webauthncbor.Marshal(&webauthncose.EC2PublicKeyData{
+		PublicKeyData: webauthncose.PublicKeyData{
+			KeyType:   int64(webauthncose.EllipticKey),
+			Algorithm: int64(webauthncose.AlgES256),
+		},
+		Curve:  1,
+		XCoord: a.key.PublicKey.X.FillBytes(make([]byte, 32)),
+		YCoord: a.key.PublicKey.Y.FillBytes(make([]byte, 32)),
+	})
+	require.NoError(t, err)
+
+	// zero aaguid, length of credential id and credential id
+	attested := make([]byte, 18)
+	binary.BigEndian.PutUint16(attested[16:], uint16(len(a.credentialID)))
+	attested = append(attested, a.credentialID...)
+	attested = append(attested, publicKey...)
+
+	attestationObject, err := webauthncbor.Marshal(map[string]interface{}{
+		"fmt":      "none",
+		"attStmt":  map[string]interface{}{},
+		"authData": a.authenticatorData(options.Response.RelyingParty.ID, a.flags()|flagAttestedData, attested),
+	})
+	require.NoError(t, err)
+
+	return a.body(t, map[string]interface{}{
+		"clientDataJSON":    encode(a.clientData(t, "webauthn.create", options.Response.Challenge)),
+		"attestationObject": encode(attestationObject),
+	})
+}
+
+// get answer navigator.credentials.get options, returns body of credential
+func (a *softwareAuthenticator) get(t *testing.T, rpID string, options *protocol.CredentialAssertion) []byte {
+	a.signCount++
+	clientData := a.clientData(t, "webauthn.get", options.Response.Challenge)
+	authData := a.authenticatorData(rpID, a.flags(), nil)
+	clientDataHash := sha256.Sum256(clientData)
+	digest := sha256.Sum256(append(append([]byte{}, authData...), clientDataHash[:]...))
+	signature, err := ecdsa.SignASN1(rand.Reader, a.key, digest[:])
+	require.NoError(t, err)
+
+	return a.body(t, map[string]interface{}{
+		"clientDataJSON":    encode(clientData),
+		"authenticatorData": encode(authData),
+		"signature":         encode(signature),
+		"userHandle":        encode(a.userHandle),
+	})
+}
+
+// body public key credential json of response
+func (a *softwareAuthenticator) body(t *testing.T, response map[string]interface{}) []byte {
+	b, err := json.Marshal(map[string]interface{}{
+		"id":         encode(a.credentialID),
+		"rawId":      encode(a.credentialID),
+		"type":       "public-key",
+		"transports": []string{"internal"},
+		"response":   response,
+	})
+	require.NoError(t, err)
+
+	return b
+}
+
+// encode base64url without padding as used by webauthn json
+func encode(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
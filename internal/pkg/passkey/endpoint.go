@@ -0,0 +1,128 @@
+// Package passkey is a webauthn passkey package
+package passkey
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/handlers"
+	"ecommerce-authen/internal/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	BeginRegistration(c *fiber.Ctx) error
+	FinishRegistration(c *fiber.Ctx) error
+	GetMyPasskeys(c *fiber.Ctx) error
+	RenamePasskey(c *fiber.Ctx) error
+	DeletePasskey(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config  *config.Configs
+	result  *config.ReturnResult
+	service Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:  config.CF,
+		result:  config.RR,
+		service: NewService(),
+	}
+}
+
+// BeginRegistration begin passkey registration
+// @Tags Passkey
+// @Summary BeginRegistration
+// @Description Returns options for navigator.credentials.create to register a passkey of current user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {object} models.PasskeyOptions
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/passkeys/register/begin [post]
+func (ep *endpoint) BeginRegistration(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.BeginRegistration)
+}
+
+// FinishRegistration finish passkey registration
+// @Tags Passkey
+// @Summary FinishRegistration
+// @Description Register passkey by credential created by navigator.credentials.create
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.PasskeyRegistrationRequest true "request body"
+// @Success 200 {object} models.Passkey
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/passkeys/register/finish [post]
+func (ep *endpoint) FinishRegistration(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.FinishRegistration, &request.PasskeyRegistrationRequest{})
+}
+
+// GetMyPasskeys get my passkeys
+// @Tags Passkey
+// @Summary GetMyPasskeys
+// @Description List passkeys of current user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {array} models.Passkey
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/passkeys [get]
+func (ep *endpoint) GetMyPasskeys(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.GetMyPasskeys)
+}
+
+// RenamePasskey rename my passkey
+// @Tags Passkey
+// @Summary RenamePasskey
+// @Description Rename passkey of current user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "passkey id"
+// @Param request body request.PasskeyRequest true "request body"
+// @Success 200 {object} models.Passkey
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/passkeys/{id} [put]
+func (ep *endpoint) RenamePasskey(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.RenamePasskey, &request.PasskeyRequest{})
+}
+
+// DeletePasskey delete my passkey
+// @Tags Passkey
+// @Summary DeletePasskey
+// @Description Delete passkey of current user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "passkey id"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/passkeys/{id} [delete]
+func (ep *endpoint) DeletePasskey(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.DeletePasskey, &request.GetOne{})
+}
@@ -0,0 +1,187 @@
+package passkey
+
+import (
+	"bytes"
+	"ecommerce-authen/internal/core/audit"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// webAuthnUser user with its passkeys for webauthn ceremonies
+type webAuthnUser struct {
+	user     *models.User
+	passkeys []models.Passkey
+}
+
+// WebAuthnID user handle, id of user
+func (u *webAuthnUser) WebAuthnID() []byte {
+	return userHandle(u.user.ID)
+}
+
+// WebAuthnName name of account shown by authenticator
+func (u *webAuthnUser) WebAuthnName() string {
+	if u.user.Email != "" {
+		return u.user.Email
+	}
+
+	if u.user.PhoneNumber != "" {
+		return u.user.PhoneNumber
+	}
+
+	return fmt.Sprintf("%d", u.user.ID)
+}
+
+// WebAuthnDisplayName display name of account shown by authenticator
+func (u *webAuthnUser) WebAuthnDisplayName() string {
+	return u.WebAuthnName()
+}
+
+// WebAuthnIcon deprecated by webauthn
+func (u *webAuthnUser) WebAuthnIcon() string {
+	return ""
+}
+
+// WebAuthnCredentials credentials of passkeys of user
+func (u *webAuthnUser) WebAuthnCredentials() []webauthn.Credential {
+	credentials := make([]webauthn.Credential, 0, len(u.passkeys))
+	for _, p := range u.passkeys {
+		credentials = append(credentials, credential(p))
+	}
+
+	return credentials
+}
+
+// userHandle user handle of user
+func userHandle(userID uint) []byte {
+	return []byte(strconv.FormatUint(uint64(userID), 10))
+}
+
+// credential webauthn credential of passkey
+func credential(p models.Passkey) webauthn.Credential {
+	transports := make([]protocol.AuthenticatorTransport, 0, len(p.Transports))
+	for _, t := range p.Transports {
+		transports = append(transports, protocol.AuthenticatorTransport(t))
+	}
+
+	return webauthn.Credential{
+		ID:              p.CredentialID,
+		PublicKey:       p.PublicKey,
+		AttestationType: p.AttestationType,
+		Transport:       transports,
+		Flags: webauthn.CredentialFlags{
+			BackupEligible: p.BackupEligible,
+			BackupState:    p.BackupState,
+		},
+		Authenticator: webauthn.Authenticator{
+			AAGUID:    p.AAGUID,
+			SignCount: p.SignCount,
+		},
+	}
+}
+
+// newPasskey passkey of user from credential created by authenticator
+func newPasskey(userID uint, cred *webauthn.Credential, nickname string) *models.Passkey {
+	transports := models.StringArray{}
+	for _, t := range cred.Transport {
+		transports = append(transports, string(t))
+	}
+
+	if nickname == "" {
+		nickname = "Passkey"
+	}
+
+	return &models.Passkey{
+		UserID:          userID,
+		CredentialID:    cred.ID,
+		PublicKey:       cred.PublicKey,
+		AttestationType: cred.AttestationType,
+		AAGUID:          cred.Authenticator.AAGUID,
+		SignCount:       cred.Authenticator.SignCount,
+		Transports:      transports,
+		BackupEligible:  cred.Flags.BackupEligible,
+		BackupState:     cred.Flags.BackupState,
+		Nickname:        nickname,
+	}
+}
+
+// webAuthn relying party by config
+func (s *service) webAuthn() (*webauthn.WebAuthn, error) {
+	cf := s.config.WebAuthn
+	return webauthn.New(&webauthn.Config{
+		RPID:          cf.RPID,
+		RPDisplayName: cf.RPDisplayName,
+		RPOrigins:     cf.RPOrigins,
+	})
+}
+
+// findWebAuthnUser find user with its passkeys
+func (s *service) findWebAuthnUser(c *context.Context, user *models.User) (*webAuthnUser, error) {
+	passkeys, err := s.passkeyRepository.FindAllByUserID(c.GetDatabase(), user.ID)
+	if err != nil {
+		logrus.Errorf("find passkeys of userID=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	return &webAuthnUser{user: user, passkeys: passkeys}, nil
+}
+
+// validateLogin validate assertion of user and keep sign count of its passkey,
+// an assertion of a cloned passkey is refused
+func (s *service) validateLogin(c *context.Context, u *webAuthnUser, session *webauthn.SessionData, parsed *protocol.ParsedCredentialAssertionData) error {
+	w, err := s.webAuthn()
+	if err != nil {
+		logrus.Errorf("webauthn config error: %s", err)
+		return err
+	}
+
+	var cred *webauthn.Credential
+	if session.UserID == nil {
+		cred, err = w.ValidateDiscoverableLogin(func(rawID, handle []byte) (webauthn.User, error) {
+			return u, nil
+		}, *session, parsed)
+	} else {
+		cred, err = w.ValidateLogin(u, *session, parsed)
+	}
+
+	if err != nil {
+		logrus.Errorf("validate passkey of userID=%d error: %s", u.user.ID, err)
+		return s.result.InvalidPasskey
+	}
+
+	for _, p := range u.passkeys {
+		if !bytes.Equal(p.CredentialID, cred.ID) {
+			continue
+		}
+
+		if cred.Authenticator.CloneWarning {
+			audit.Security(audit.EventPasskeyCloned, logrus.Fields{
+				"user_id":    u.user.ID,
+				"passkey_id": p.ID,
+				"ip":         c.IP(),
+				"user_agent": c.Get(fiber.HeaderUserAgent),
+			})
+			return s.result.InvalidPasskey
+		}
+
+		now := time.Now()
+		p.SignCount = cred.Authenticator.SignCount
+		p.BackupState = cred.Flags.BackupState
+		p.LastUsedAt = &now
+		if err := s.passkeyRepository.Update(c.GetDatabase(), &p); err != nil {
+			logrus.Errorf("update passkey id=%d error: %s", p.ID, err)
+			return err
+		}
+
+		return nil
+	}
+
+	return s.result.InvalidPasskey
+}
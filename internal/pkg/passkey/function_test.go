@@ -0,0 +1,192 @@
+package passkey
+
+import (
+	"bytes"
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/models"
+	"testing"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const (
+	testRPID   = "localhost"
+	testOrigin = "https://localhost:3000"
+)
+
+// newTestService service with relying party of test origin
+func newTestService() *service {
+	cf := &config.Configs{}
+	cf.WebAuthn.RPID = testRPID
+	cf.WebAuthn.RPDisplayName = "Ecommerce"
+	cf.WebAuthn.RPOrigins = []string{testOrigin}
+	return &service{config: cf, result: config.RR}
+}
+
+// newTestUser user of id
+func newTestUser(id uint, email, phoneNumber string) *models.User {
+	user := &models.User{Email: email, PhoneNumber: phoneNumber}
+	user.ID = id
+	return user
+}
+
+// register run registration ceremony of user with authenticator, returns passkey to keep
+func register(t *testing.T, s *service, user *models.User, a *softwareAuthenticator) *models.Passkey {
+	w, err := s.webAuthn()
+	require.NoError(t, err)
+
+	options, session, err := w.BeginRegistration(&webAuthnUser{user: user})
+	require.NoError(t, err)
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(a.create(t, options)))
+	require.NoError(t, err)
+
+	cred, err := w.CreateCredential(&webAuthnUser{user: user}, *session, parsed)
+	require.NoError(t, err)
+
+	return newPasskey(user.ID, cred, "")
+}
+
+func TestRegistrationAndLogin(t *testing.T) {
+	s := newTestService()
+	user := newTestUser(7, "test@hotmail.com", "")
+	a := newSoftwareAuthenticator(t, testOrigin)
+
+	passkey := register(t, s, user, a)
+	assert.Equal(t, uint(7), passkey.UserID)
+	assert.Equal(t, a.credentialID, passkey.CredentialID)
+	assert.Equal(t, "Passkey", passkey.Nickname)
+	assert.Equal(t, "none", passkey.AttestationType)
+	assert.Equal(t, models.StringArray{"internal"}, passkey.Transports)
+	assert.Equal(t, userHandle(7), a.userHandle)
+
+	w, err := s.webAuthn()
+	require.NoError(t, err)
+
+	u := &webAuthnUser{user: user, passkeys: []models.Passkey{*passkey}}
+	options, session, err := w.BeginLogin(u)
+	require.NoError(t, err)
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(a.get(t, testRPID, options)))
+	require.NoError(t, err)
+
+	cred, err := w.ValidateLogin(u, *session, parsed)
+	require.NoError(t, err)
+	assert.Equal(t, uint32(1), cred.Authenticator.SignCount)
+	assert.False(t, cred.Authenticator.CloneWarning)
+}
+
+func TestDiscoverableLogin(t *testing.T) {
+	tests := []struct {
+		name         string
+		userVerified bool
+		wantErr      bool
+	}{
+		{name: "user verified", userVerified: true},
+		{name: "user not verified", userVerified: false, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService()
+			user := newTestUser(7, "test@hotmail.com", "")
+			a := newSoftwareAuthenticator(t, testOrigin)
+			passkey := register(t, s, user, a)
+			a.userVerified = tt.userVerified
+
+			w, err := s.webAuthn()
+			require.NoError(t, err)
+
+			options, session, err := w.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+			require.NoError(t, err)
+
+			parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(a.get(t, testRPID, options)))
+			require.NoError(t, err)
+			assert.Equal(t, userHandle(7), []byte(parsed.Response.UserHandle))
+
+			u := &webAuthnUser{user: user, passkeys: []models.Passkey{*passkey}}
+			_, err = w.ValidateDiscoverableLogin(func(rawID, handle []byte) (webauthn.User, error) {
+				return u, nil
+			}, *session, parsed)
+			assert.Equal(t, tt.wantErr, err != nil)
+		})
+	}
+}
+
+func TestCloneWarning(t *testing.T) {
+	tests := []struct {
+		name       string
+		storedSign uint32
+		want       bool
+	}{
+		{name: "sign count increased", storedSign: 0, want: false},
+		{name: "sign count went back", storedSign: 10, want: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := newTestService()
+			user := newTestUser(7, "test@hotmail.com", "")
+			a := newSoftwareAuthenticator(t, testOrigin)
+			passkey := register(t, s, user, a)
+			passkey.SignCount = tt.storedSign
+
+			w, err := s.webAuthn()
+			require.NoError(t, err)
+
+			u := &webAuthnUser{user: user, passkeys: []models.Passkey{*passkey}}
+			options, session, err := w.BeginLogin(u)
+			require.NoError(t, err)
+
+			a.signCount = 4
+			parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(a.get(t, testRPID, options)))
+			require.NoError(t, err)
+
+			cred, err := w.ValidateLogin(u, *session, parsed)
+			require.NoError(t, err)
+			assert.Equal(t, tt.want, cred.Authenticator.CloneWarning)
+		})
+	}
+}
+
+func TestOtherOriginRefused(t *testing.T) {
+	s := newTestService()
+	user := newTestUser(7, "test@hotmail.com", "")
+	a := newSoftwareAuthenticator(t, "https://evil.example.com")
+
+	w, err := s.webAuthn()
+	require.NoError(t, err)
+
+	options, session, err := w.BeginRegistration(&webAuthnUser{user: user})
+	require.NoError(t, err)
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(a.create(t, options)))
+	require.NoError(t, err)
+
+	_, err = w.CreateCredential(&webAuthnUser{user: user}, *session, parsed)
+	assert.Error(t, err)
+}
+
+func TestWebAuthnName(t *testing.T) {
+	tests := []struct {
+		name       string
+		user       *models.User
+		want       string
+		wantHandle string
+	}{
+		{name: "email", user: newTestUser(1, "test@hotmail.com", "0812345678"), want: "test@hotmail.com", wantHandle: "1"},
+		{name: "phone number", user: newTestUser(1, "", "0812345678"), want: "0812345678", wantHandle: "1"},
+		{name: "id", user: newTestUser(12, "", ""), want: "12", wantHandle: "12"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			u := &webAuthnUser{user: tt.user}
+			assert.Equal(t, tt.want, u.WebAuthnName())
+			assert.Equal(t, []byte(tt.wantHandle), u.WebAuthnID())
+		})
+	}
+}
@@ -0,0 +1,330 @@
+package passkey
+
+import (
+	"bytes"
+	"ecommerce-authen/internal/core/audit"
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/utils"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/repositories"
+	"ecommerce-authen/internal/request"
+	"errors"
+
+	"github.com/go-webauthn/webauthn/protocol"
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// Service service interface
+type Service interface {
+	BeginRegistration(c *context.Context) (*models.PasskeyOptions, error)
+	FinishRegistration(c *context.Context, request *request.PasskeyRegistrationRequest) (*models.Passkey, error)
+	GetMyPasskeys(c *context.Context) ([]models.Passkey, error)
+	RenamePasskey(c *context.Context, request *request.PasskeyRequest) (*models.Passkey, error)
+	DeletePasskey(c *context.Context, request *request.GetOne) error
+	BeginLogin(c *context.Context) (*models.PasskeyOptions, error)
+	FinishLogin(c *context.Context, request *request.PasskeyLoginRequest) (*models.User, error)
+	BeginSecondFactor(c *context.Context, user *models.User, twoFactorToken string) (*models.PasskeyOptions, error)
+	FinishSecondFactor(c *context.Context, user *models.User, request *request.PasskeyLoginRequest) error
+}
+
+type service struct {
+	config            *config.Configs
+	result            *config.ReturnResult
+	userRepository    repositories.UserRepository
+	passkeyRepository repositories.PasskeyRepository
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config:            config.CF,
+		result:            config.RR,
+		userRepository:    repositories.UserNewRepository(),
+		passkeyRepository: repositories.PasskeyNewRepository(),
+	}
+}
+
+// BeginRegistration start registration ceremony of passkey of current user,
+// passkeys already registered are excluded
+func (s *service) BeginRegistration(c *context.Context) (*models.PasskeyOptions, error) {
+	if c.GetClaims().IsImpersonation() {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), c.GetUserID(), user); err != nil {
+		logrus.Errorf("find user by id=%d error: %s", c.GetUserID(), err)
+		return nil, err
+	}
+
+	u, err := s.findWebAuthnUser(c, user)
+	if err != nil {
+		return nil, err
+	}
+
+	w, err := s.webAuthn()
+	if err != nil {
+		logrus.Errorf("webauthn config error: %s", err)
+		return nil, err
+	}
+
+	exclusions := []protocol.CredentialDescriptor{}
+	for _, cred := range u.WebAuthnCredentials() {
+		exclusions = append(exclusions, cred.Descriptor())
+	}
+
+	options, session, err := w.BeginRegistration(u,
+		webauthn.WithExclusions(exclusions),
+		webauthn.WithResidentKeyRequirement(protocol.ResidentKeyRequirementPreferred),
+	)
+	if err != nil {
+		logrus.Errorf("begin passkey registration error: %s", err)
+		return nil, err
+	}
+
+	if err := s.storeSession(registrationKey(user.ID), session); err != nil {
+		return nil, err
+	}
+
+	return &models.PasskeyOptions{Options: options}, nil
+}
+
+// FinishRegistration verify credential created by authenticator and keep it as passkey of current user
+func (s *service) FinishRegistration(c *context.Context, request *request.PasskeyRegistrationRequest) (*models.Passkey, error) {
+	if c.GetClaims().IsImpersonation() {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	session, err := s.takeSession(registrationKey(c.GetUserID()))
+	if err != nil {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	parsed, err := protocol.ParseCredentialCreationResponseBody(bytes.NewReader(request.Credential))
+	if err != nil {
+		return nil, s.result.InvalidPasskey
+	}
+
+	db := c.GetDatabase()
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(db, c.GetUserID(), user); err != nil {
+		logrus.Errorf("find user by id=%d error: %s", c.GetUserID(), err)
+		return nil, err
+	}
+
+	w, err := s.webAuthn()
+	if err != nil {
+		logrus.Errorf("webauthn config error: %s", err)
+		return nil, err
+	}
+
+	cred, err := w.CreateCredential(&webAuthnUser{user: user}, *session, parsed)
+	if err != nil {
+		logrus.Errorf("create passkey of userID=%d error: %s", user.ID, err)
+		return nil, s.result.InvalidPasskey
+	}
+
+	passkey := newPasskey(user.ID, cred, request.Nickname)
+	if err := s.passkeyRepository.Create(db, passkey); err != nil {
+		logrus.Errorf("create passkey of userID=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	s.audit(c, audit.EventPasskeyRegistered, user.ID, passkey.ID)
+	return passkey, nil
+}
+
+// GetMyPasskeys get passkeys of current user
+func (s *service) GetMyPasskeys(c *context.Context) ([]models.Passkey, error) {
+	passkeys, err := s.passkeyRepository.FindAllByUserID(c.GetDatabase(), c.GetUserID())
+	if err != nil {
+		logrus.Errorf("find passkeys of userID=%d error: %s", c.GetUserID(), err)
+		return nil, err
+	}
+
+	return passkeys, nil
+}
+
+// RenamePasskey rename passkey of current user
+func (s *service) RenamePasskey(c *context.Context, request *request.PasskeyRequest) (*models.Passkey, error) {
+	if c.GetClaims().IsImpersonation() {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	db := c.GetDatabase()
+	passkey, err := s.passkeyRepository.FindUserPasskey(db, c.GetUserID(), request.ID)
+	if err != nil {
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	passkey.Nickname = request.Nickname
+	if err := s.passkeyRepository.Update(db, passkey); err != nil {
+		logrus.Errorf("update passkey id=%d error: %s", passkey.ID, err)
+		return nil, err
+	}
+
+	return passkey, nil
+}
+
+// DeletePasskey delete passkey of current user
+func (s *service) DeletePasskey(c *context.Context, request *request.GetOne) error {
+	if c.GetClaims().IsImpersonation() {
+		return s.result.InvalidPermissionRole
+	}
+
+	db := c.GetDatabase()
+	passkey, err := s.passkeyRepository.FindUserPasskey(db, c.GetUserID(), request.ID)
+	if err != nil {
+		return s.result.Internal.DatabaseNotFound
+	}
+
+	if err := s.passkeyRepository.HardDelete(db, passkey); err != nil {
+		logrus.Errorf("delete passkey id=%d error: %s", passkey.ID, err)
+		return err
+	}
+
+	s.audit(c, audit.EventPasskeyDeleted, passkey.UserID, passkey.ID)
+	return nil
+}
+
+// BeginLogin start passwordless login ceremony, any passkey discoverable by authenticator can be used,
+// user verification is required as the passkey is the first factor
+func (s *service) BeginLogin(c *context.Context) (*models.PasskeyOptions, error) {
+	w, err := s.webAuthn()
+	if err != nil {
+		logrus.Errorf("webauthn config error: %s", err)
+		return nil, err
+	}
+
+	options, session, err := w.BeginDiscoverableLogin(webauthn.WithUserVerification(protocol.VerificationRequired))
+	if err != nil {
+		logrus.Errorf("begin passkey login error: %s", err)
+		return nil, err
+	}
+
+	loginToken, err := utils.RandomToken(32)
+	if err != nil {
+		logrus.Errorf("generate passkey login token error: %s", err)
+		return nil, err
+	}
+
+	if err := s.storeSession(loginKey(loginToken), session); err != nil {
+		return nil, err
+	}
+
+	return &models.PasskeyOptions{Token: loginToken, Options: options}, nil
+}
+
+// FinishLogin verify assertion of passkey of passwordless login and find its user,
+// caller signs the user in like after every other first step of sign in
+func (s *service) FinishLogin(c *context.Context, request *request.PasskeyLoginRequest) (*models.User, error) {
+	session, err := s.takeSession(loginKey(request.Token))
+	if err != nil {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(request.Credential))
+	if err != nil {
+		return nil, s.result.InvalidPasskey
+	}
+
+	db := c.GetDatabase()
+	passkey, err := s.passkeyRepository.FindByCredentialID(db, parsed.RawID)
+	if err != nil {
+		if !errors.Is(err, gorm.ErrRecordNotFound) {
+			logrus.Errorf("find passkey by credential id error: %s", err)
+			return nil, err
+		}
+
+		return nil, s.result.InvalidPasskey
+	}
+
+	if !bytes.Equal(parsed.Response.UserHandle, userHandle(passkey.UserID)) {
+		return nil, s.result.InvalidPasskey
+	}
+
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(db, passkey.UserID, user); err != nil {
+		logrus.Errorf("find user by id=%d error: %s", passkey.UserID, err)
+		return nil, s.result.InvalidPasskey
+	}
+
+	u, err := s.findWebAuthnUser(c, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.validateLogin(c, u, session, parsed); err != nil {
+		return nil, err
+	}
+
+	if user.Deactivated() {
+		return nil, s.result.BlockedUser
+	}
+
+	return user, nil
+}
+
+// BeginSecondFactor start ceremony of second step of sign in by passkeys of user
+func (s *service) BeginSecondFactor(c *context.Context, user *models.User, twoFactorToken string) (*models.PasskeyOptions, error) {
+	u, err := s.findWebAuthnUser(c, user)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(u.passkeys) == 0 {
+		return nil, s.result.InvalidPasskey
+	}
+
+	w, err := s.webAuthn()
+	if err != nil {
+		logrus.Errorf("webauthn config error: %s", err)
+		return nil, err
+	}
+
+	options, session, err := w.BeginLogin(u)
+	if err != nil {
+		logrus.Errorf("begin passkey second factor error: %s", err)
+		return nil, err
+	}
+
+	if err := s.storeSession(secondFactorKey(twoFactorToken), session); err != nil {
+		return nil, err
+	}
+
+	return &models.PasskeyOptions{Token: twoFactorToken, Options: options}, nil
+}
+
+// FinishSecondFactor verify assertion of passkey of user for second step of sign in
+func (s *service) FinishSecondFactor(c *context.Context, user *models.User, request *request.PasskeyLoginRequest) error {
+	session, err := s.takeSession(secondFactorKey(request.Token))
+	if err != nil {
+		return s.result.InvalidCodeOrExpired
+	}
+
+	parsed, err := protocol.ParseCredentialRequestResponseBody(bytes.NewReader(request.Credential))
+	if err != nil {
+		return s.result.InvalidPasskey
+	}
+
+	u, err := s.findWebAuthnUser(c, user)
+	if err != nil {
+		return err
+	}
+
+	return s.validateLogin(c, u, session, parsed)
+}
+
+// audit emit security event of passkey
+func (s *service) audit(c *context.Context, event audit.Event, userID, passkeyID uint) {
+	audit.Security(event, logrus.Fields{
+		"user_id":    userID,
+		"passkey_id": passkeyID,
+		"ip":         c.IP(),
+		"user_agent": c.Get(fiber.HeaderUserAgent),
+	})
+}
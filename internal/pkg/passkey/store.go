@@ -0,0 +1,66 @@
+package passkey
+
+import (
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/pkg/token"
+	"encoding/json"
+	"fmt"
+
+	"github.com/go-webauthn/webauthn/webauthn"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	ceremonyKeyPrefix = "passkey_ceremony:"
+)
+
+// registrationKey redis key of registration ceremony of user
+func registrationKey(userID uint) string {
+	return fmt.Sprintf("%sregistration:%d", ceremonyKeyPrefix, userID)
+}
+
+// loginKey redis key of login ceremony by hash of its token
+func loginKey(loginToken string) string {
+	return ceremonyKeyPrefix + "login:" + token.Hash(loginToken)
+}
+
+// secondFactorKey redis key of ceremony of second step of sign in by hash of two factor token
+func secondFactorKey(twoFactorToken string) string {
+	return ceremonyKeyPrefix + "two_factor:" + token.Hash(twoFactorToken)
+}
+
+// storeSession store session data of ceremony until it expires,
+// session data is kept as json as its extensions can not be gob encoded
+func (s *service) storeSession(key string, session *webauthn.SessionData) error {
+	b, err := json.Marshal(session)
+	if err != nil {
+		return err
+	}
+
+	if err := redis.GetConnection().Set(key, string(b), s.config.WebAuthn.CeremonyExpireTime); err != nil {
+		logrus.Errorf("set passkey ceremony error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// takeSession find session data of ceremony and delete it, a ceremony can only be finished once
+func (s *service) takeSession(key string) (*webauthn.SessionData, error) {
+	conn := redis.GetConnection()
+	var b string
+	if err := conn.Get(key, &b); err != nil {
+		return nil, err
+	}
+
+	if err := conn.Delete(key); err != nil {
+		logrus.Errorf("delete passkey ceremony error: %s", err)
+	}
+
+	session := &webauthn.SessionData{}
+	if err := json.Unmarshal([]byte(b), session); err != nil {
+		return nil, err
+	}
+
+	return session, nil
+}
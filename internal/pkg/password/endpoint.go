@@ -0,0 +1,68 @@
+// Package password is a user password package
+package password
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/handlers"
+	"ecommerce-authen/internal/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	ChangePassword(c *fiber.Ctx) error
+	ChangeExpiredPassword(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config  *config.Configs
+	result  *config.ReturnResult
+	service Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:  config.CF,
+		result:  config.RR,
+		service: NewService(),
+	}
+}
+
+// ChangePassword change my password
+// @Tags Password
+// @Summary ChangePassword
+// @Description Change password of current user, the last passwords can not be used again
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.ChangePasswordRequest true "request body"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/password [post]
+func (ep *endpoint) ChangePassword(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.ChangePassword, &request.ChangePasswordRequest{})
+}
+
+// ChangeExpiredPassword change expired password
+// @Tags Password
+// @Summary ChangeExpiredPassword
+// @Description Change expired password by change_password_token of login response and sign in
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.ChangeExpiredPasswordRequest true "request body"
+// @Success 200 {object} models.RefreshToken
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/password/change [post]
+func (ep *endpoint) ChangeExpiredPassword(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.ChangeExpiredPassword, &request.ChangeExpiredPasswordRequest{})
+}
@@ -0,0 +1,90 @@
+package password
+
+import (
+	"ecommerce-authen/internal/core/bcrypt"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/utils"
+	"ecommerce-authen/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// checkPassword password meets password policy and was not used recently by user
+func (s *service) checkPassword(c *context.Context, user *models.User, password string) error {
+	if !utils.IsValidPassword(password) {
+		return s.result.InvalidPassword
+	}
+
+	used, err := s.usedRecently(c, user, password)
+	if err != nil {
+		return err
+	}
+
+	if used {
+		return s.result.AlreadyUsedLastPassword
+	}
+
+	return nil
+}
+
+// usedRecently password is current password or one of the last passwords of user
+func (s *service) usedRecently(c *context.Context, user *models.User, password string) (bool, error) {
+	if user.Password != "" && bcrypt.ComparePassword(user.Password, password) {
+		return true, nil
+	}
+
+	size := s.config.PasswordPolicy.HistorySize
+	if size <= 0 {
+		return false, nil
+	}
+
+	histories, err := s.passwordHistoryRepository.FindLatest(c.GetDatabase(), user.ID, size)
+	if err != nil {
+		logrus.Errorf("find password history of userID=%d error: %s", user.ID, err)
+		return false, err
+	}
+
+	for _, h := range histories {
+		if bcrypt.ComparePassword(h.Password, password) {
+			return true, nil
+		}
+	}
+
+	return false, nil
+}
+
+// recordPassword keep password hash of user in history, only the last passwords are kept
+func (s *service) recordPassword(c *context.Context, user *models.User) error {
+	size := s.config.PasswordPolicy.HistorySize
+	if size <= 0 {
+		return nil
+	}
+
+	db := c.GetDatabase()
+	err := s.passwordHistoryRepository.Create(db, &models.PasswordHistory{
+		UserID:   user.ID,
+		Password: user.Password,
+	})
+	if err != nil {
+		logrus.Errorf("create password history of userID=%d error: %s", user.ID, err)
+		return err
+	}
+
+	if err := s.passwordHistoryRepository.DeleteOlder(db, user.ID, size); err != nil {
+		logrus.Errorf("delete password history of userID=%d error: %s", user.ID, err)
+		return err
+	}
+
+	return nil
+}
+
+// maxAge maximum password age of role, roles without their own maximum use the global one
+func (s *service) maxAge(role models.UserRole) time.Duration {
+	cf := s.config.PasswordPolicy
+	if maxAge, ok := cf.RoleMaxAges[role.Name()]; ok {
+		return maxAge
+	}
+
+	return cf.MaxAge
+}
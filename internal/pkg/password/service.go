@@ -0,0 +1,190 @@
+package password
+
+import (
+	"ecommerce-authen/internal/core/audit"
+	"ecommerce-authen/internal/core/bcrypt"
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/utils"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/repositories"
+	"ecommerce-authen/internal/request"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// Service service interface
+type Service interface {
+	ChangePassword(c *context.Context, request *request.ChangePasswordRequest) error
+	SetPassword(c *context.Context, user *models.User, password string) error
+	Expired(user *models.User) bool
+	IssueChangeToken(user *models.User) (*models.RefreshToken, error)
+	ChangeExpiredPassword(c *context.Context, request *request.ChangeExpiredPasswordRequest) (*models.RefreshToken, error)
+}
+
+type service struct {
+	config                    *config.Configs
+	result                    *config.ReturnResult
+	userRepository            repositories.UserRepository
+	passwordHistoryRepository repositories.PasswordHistoryRepository
+	tokenService              token.Service
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config:                    config.CF,
+		result:                    config.RR,
+		userRepository:            repositories.UserNewRepository(),
+		passwordHistoryRepository: repositories.PasswordHistoryNewRepository(),
+		tokenService:              token.NewService(),
+	}
+}
+
+// ChangePassword change password of current user
+func (s *service) ChangePassword(c *context.Context, request *request.ChangePasswordRequest) error {
+	if c.GetClaims().IsImpersonation() {
+		return s.result.InvalidPermissionRole
+	}
+
+	if request.Password != request.ConfirmPassword {
+		return s.result.PasswordNotMatch
+	}
+
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), c.GetUserID(), user); err != nil {
+		logrus.Errorf("find user by id=%d error: %s", c.GetUserID(), err)
+		return err
+	}
+
+	if !bcrypt.ComparePassword(user.Password, request.CurrentPassword) {
+		return s.result.InvalidPassword
+	}
+
+	if err := s.SetPassword(c, user, request.Password); err != nil {
+		return err
+	}
+
+	if request.RevokeOtherSessions {
+		if err := s.tokenService.RevokeSessions(c, user.ID, c.GetSessionID()); err != nil {
+			return err
+		}
+	}
+
+	audit.Security(audit.EventPasswordChanged, logrus.Fields{
+		"user_id":       user.ID,
+		"revoke_others": request.RevokeOtherSessions,
+		"ip":            c.IP(),
+		"user_agent":    c.Get(fiber.HeaderUserAgent),
+	})
+
+	return nil
+}
+
+// SetPassword set new password of user by password policy,
+// the last passwords of user can not be used again
+func (s *service) SetPassword(c *context.Context, user *models.User, password string) error {
+	if err := s.checkPassword(c, user, password); err != nil {
+		return err
+	}
+
+	passwordHash, err := bcrypt.GeneratePassword(password)
+	if err != nil {
+		return err
+	}
+
+	db := c.GetDatabase()
+	now := time.Now()
+	user.Password = passwordHash
+	user.PasswordChangedAt = &now
+	user.PasswordResetRequired = false
+	if err := s.userRepository.Update(db, user); err != nil {
+		logrus.Errorf("update password of userID=%d error: %s", user.ID, err)
+		return err
+	}
+
+	return s.recordPassword(c, user)
+}
+
+// Expired password of user is older than maximum password age of its role
+func (s *service) Expired(user *models.User) bool {
+	maxAge := s.maxAge(user.Role)
+	if maxAge <= 0 || user.Password == "" {
+		return false
+	}
+
+	changedAt := user.CreatedAt
+	if user.PasswordChangedAt != nil {
+		changedAt = *user.PasswordChangedAt
+	}
+
+	return time.Since(changedAt) > maxAge
+}
+
+// IssueChangeToken issue token which can only be used to change expired password,
+// no session is created until the password was changed
+func (s *service) IssueChangeToken(user *models.User) (*models.RefreshToken, error) {
+	changeToken, err := utils.RandomToken(32)
+	if err != nil {
+		logrus.Errorf("generate change password token error: %s", err)
+		return nil, err
+	}
+
+	if err := s.storeChangeToken(changeToken, user.ID); err != nil {
+		return nil, err
+	}
+
+	return &models.RefreshToken{
+		UserID:              user.ID,
+		Role:                user.Role,
+		ChangePasswordToken: changeToken,
+	}, nil
+}
+
+// ChangeExpiredPassword change expired password by change password token and sign in
+func (s *service) ChangeExpiredPassword(c *context.Context, request *request.ChangeExpiredPasswordRequest) (*models.RefreshToken, error) {
+	if request.Password != request.ConfirmPassword {
+		return nil, s.result.PasswordNotMatch
+	}
+
+	userID, err := s.findChangeTokenUser(request.Token)
+	if err != nil {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), userID, user); err != nil {
+		logrus.Errorf("find user by id=%d error: %s", userID, err)
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	if user.Deactivated() {
+		return nil, s.result.BlockedUser
+	}
+
+	// a rejected password keeps the token, so the user can try another one
+	if err := s.checkPassword(c, user, request.Password); err != nil {
+		return nil, err
+	}
+
+	// claimed before the password is set so concurrent requests change it only once
+	if claimedID, err := s.claimChangeToken(request.Token); err != nil || claimedID != user.ID {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	if err := s.SetPassword(c, user, request.Password); err != nil {
+		return nil, err
+	}
+
+	audit.Security(audit.EventPasswordChanged, logrus.Fields{
+		"user_id":    user.ID,
+		"expired":    true,
+		"ip":         c.IP(),
+		"user_agent": c.Get(fiber.HeaderUserAgent),
+	})
+
+	return s.tokenService.Create(c, user, token.Grant{LoginType: models.LoginTypeNormal})
+}
@@ -0,0 +1,44 @@
+package password
+
+import (
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/pkg/token"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	changeTokenKeyPrefix = "change_password_token:"
+)
+
+// storeChangeToken store user of change password token until it expires
+func (s *service) storeChangeToken(changeToken string, userID uint) error {
+	err := redis.GetConnection().Set(changeTokenKeyPrefix+token.Hash(changeToken), userID, s.config.PasswordPolicy.ChangeTokenExpireTime)
+	if err != nil {
+		logrus.Errorf("set change password token error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findChangeTokenUser find user of change password token
+func (s *service) findChangeTokenUser(changeToken string) (uint, error) {
+	var userID uint
+	if err := redis.GetConnection().Get(changeTokenKeyPrefix+token.Hash(changeToken), &userID); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
+
+// claimChangeToken get user of change password token and delete the token in one transaction,
+// so a token is used only once
+func (s *service) claimChangeToken(changeToken string) (uint, error) {
+	var userID uint
+	if err := redis.GetConnection().GetDelete(changeTokenKeyPrefix+token.Hash(changeToken), &userID); err != nil {
+		return 0, err
+	}
+
+	return userID, nil
+}
@@ -0,0 +1,164 @@
+package rbac
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/handlers"
+	"ecommerce-authen/internal/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	GetPermissions(c *fiber.Ctx) error
+	GetRoles(c *fiber.Ctx) error
+	CreateRole(c *fiber.Ctx) error
+	UpdateRole(c *fiber.Ctx) error
+	DeleteRole(c *fiber.Ctx) error
+	GetUserRoles(c *fiber.Ctx) error
+	UpdateUserRoles(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config  *config.Configs
+	result  *config.ReturnResult
+	service Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:  config.CF,
+		result:  config.RR,
+		service: NewService(),
+	}
+}
+
+// GetPermissions get permissions
+// @Tags Admin
+// @Summary Get permissions
+// @Description Get permissions known by authen service, roles may hold permissions of other services too
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {array} string
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/permissions [get]
+func (ep *endpoint) GetPermissions(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.GetPermissions)
+}
+
+// GetRoles get roles
+// @Tags Admin
+// @Summary Get roles
+// @Description Get roles and their permissions
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {array} models.Role
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/roles [get]
+func (ep *endpoint) GetRoles(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.GetRoles)
+}
+
+// CreateRole create role
+// @Tags Admin
+// @Summary Create role
+// @Description Create role with permissions
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.RoleRequest true "request body"
+// @Success 200 {object} models.Role
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/roles [post]
+func (ep *endpoint) CreateRole(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.CreateRole, &request.RoleRequest{})
+}
+
+// UpdateRole update role
+// @Tags Admin
+// @Summary Update role
+// @Description Update role, seeded roles can not be renamed
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "role id"
+// @Param request body request.RoleRequest true "request body"
+// @Success 200 {object} models.Role
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/roles/{id} [put]
+func (ep *endpoint) UpdateRole(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.UpdateRole, &request.RoleRequest{})
+}
+
+// DeleteRole delete role
+// @Tags Admin
+// @Summary Delete role
+// @Description Delete role and unassign it from users, seeded roles can not be deleted
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "role id"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/roles/{id} [delete]
+func (ep *endpoint) DeleteRole(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.DeleteRole, &request.GetOne{})
+}
+
+// GetUserRoles get roles of user
+// @Tags Admin
+// @Summary Get roles of user
+// @Description Get role of user role and roles assigned to user
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Success 200 {array} models.Role
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/roles [get]
+func (ep *endpoint) GetUserRoles(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.GetUserRoles, &request.GetOne{})
+}
+
+// UpdateUserRoles update roles of user
+// @Tags Admin
+// @Summary Update roles of user
+// @Description Replace roles assigned to user, role of user role is always kept
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Param request body request.UserRolesRequest true "request body"
+// @Success 200 {array} models.Role
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/roles [put]
+func (ep *endpoint) UpdateUserRoles(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.UpdateUserRoles, &request.UserRolesRequest{})
+}
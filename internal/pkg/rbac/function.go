@@ -0,0 +1,110 @@
+package rbac
+
+import (
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/models"
+	"errors"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+// userRoleNames names of roles of user, cached until roles of user change
+func (s *service) userRoleNames(c *context.Context, userID uint) ([]string, error) {
+	key := fmt.Sprintf("%s%d", userRolesKeyPrefix, userID)
+	names := []string{}
+	if err := redis.GetConnection().Get(key, &names); err == nil {
+		return names, nil
+	}
+
+	db := c.GetDatabase()
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(db, userID, user); err != nil {
+		logrus.Errorf("find user id=%d error: %s", userID, err)
+		return nil, err
+	}
+
+	roles, err := s.roleRepository.FindUserRoles(db, user.ID, user.Role)
+	if err != nil {
+		logrus.Errorf("find roles of user id=%d error: %s", userID, err)
+		return nil, err
+	}
+
+	for _, role := range roles {
+		names = append(names, role.Name)
+	}
+
+	if err := redis.GetConnection().Set(key, names, s.config.RBAC.PermissionCacheTime); err != nil {
+		logrus.Errorf("cache roles of user id=%d error: %s", userID, err)
+	}
+
+	return names, nil
+}
+
+// rolePermissions permissions of role, cached until role changes,
+// a role deleted or renamed after roles of user were cached grants nothing
+func (s *service) rolePermissions(c *context.Context, name string) ([]string, error) {
+	key := rolePermissionsKeyPrefix + name
+	permissions := []string{}
+	if err := redis.GetConnection().Get(key, &permissions); err == nil {
+		return permissions, nil
+	}
+
+	role, err := s.roleRepository.FindByName(c.GetDatabase(), name)
+	if err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			logrus.Warnf("role name=%s of cached user roles not found", name)
+			return permissions, nil
+		}
+
+		logrus.Errorf("find role name=%s error: %s", name, err)
+		return nil, err
+	}
+
+	permissions = role.Permissions
+	if err := redis.GetConnection().Set(key, permissions, s.config.RBAC.PermissionCacheTime); err != nil {
+		logrus.Errorf("cache permissions of role name=%s error: %s", name, err)
+	}
+
+	return permissions, nil
+}
+
+// checkGrantable signed in user holds every one of permissions,
+// nobody gives away permissions they do not have themselves
+func (s *service) checkGrantable(c *context.Context, permissions []string) error {
+	ok, err := s.CanGrant(c, permissions)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return s.result.InvalidPermissionRole
+	}
+
+	return nil
+}
+
+// clearUsersRoles clear cached roles of users after a role of them was renamed or deleted
+func (s *service) clearUsersRoles(userIDs []uint) {
+	for _, id := range userIDs {
+		if err := s.ClearUserRoles(id); err != nil {
+			logrus.Errorf("clear cached roles of user id=%d error: %s", id, err)
+		}
+	}
+}
+
+// uniqueIDs ids without duplicates
+func uniqueIDs(ids []uint) []uint {
+	seen := map[uint]bool{}
+	unique := []uint{}
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+
+	return unique
+}
@@ -0,0 +1,316 @@
+// Package rbac is a role based access control package
+package rbac
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/repositories"
+	"ecommerce-authen/internal/request"
+	"fmt"
+
+	"github.com/sirupsen/logrus"
+	"gorm.io/gorm"
+)
+
+const (
+	// userRolesKeyPrefix cache of role names of user
+	userRolesKeyPrefix = "user_roles:"
+	// rolePermissionsKeyPrefix cache of permissions of role
+	rolePermissionsKeyPrefix = "role_permissions:"
+)
+
+// Service service interface
+type Service interface {
+	UserPermissions(c *context.Context, userID uint) ([]string, error)
+	RolePermissions(c *context.Context, name string) ([]string, error)
+	CanGrant(c *context.Context, permissions []string) (bool, error)
+	CanManageUser(c *context.Context, userID uint) (bool, error)
+	ClearUserRoles(userID uint) error
+	GetPermissions(c *context.Context) ([]string, error)
+	GetRoles(c *context.Context) ([]models.Role, error)
+	CreateRole(c *context.Context, request *request.RoleRequest) (*models.Role, error)
+	UpdateRole(c *context.Context, request *request.RoleRequest) (*models.Role, error)
+	DeleteRole(c *context.Context, request *request.GetOne) error
+	GetUserRoles(c *context.Context, request *request.GetOne) ([]models.Role, error)
+	UpdateUserRoles(c *context.Context, request *request.UserRolesRequest) ([]models.Role, error)
+}
+
+type service struct {
+	config         *config.Configs
+	result         *config.ReturnResult
+	roleRepository repositories.RoleRepository
+	userRepository repositories.UserRepository
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config:         config.CF,
+		result:         config.RR,
+		roleRepository: repositories.RoleNewRepository(),
+		userRepository: repositories.UserNewRepository(),
+	}
+}
+
+// SeedDefaultRoles create roles of user roles when they do not exist yet
+func SeedDefaultRoles(db *gorm.DB) error {
+	return repositories.RoleNewRepository().Seed(db, models.DefaultRoles())
+}
+
+// UserPermissions permissions of every role of user, resolved on each request
+// so changed roles apply without waiting for tokens to be renewed
+func (s *service) UserPermissions(c *context.Context, userID uint) ([]string, error) {
+	names, err := s.userRoleNames(c, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	permissions := []string{}
+	for _, name := range names {
+		p, err := s.rolePermissions(c, name)
+		if err != nil {
+			return nil, err
+		}
+
+		permissions = append(permissions, p...)
+	}
+
+	return permissions, nil
+}
+
+// RolePermissions permissions of role by name, a missing role grants nothing
+func (s *service) RolePermissions(c *context.Context, name string) ([]string, error) {
+	return s.rolePermissions(c, name)
+}
+
+// CanGrant signed in user holds every one of permissions, so may give them to others
+func (s *service) CanGrant(c *context.Context, permissions []string) (bool, error) {
+	held, err := s.UserPermissions(c, c.GetUserID())
+	if err != nil {
+		return false, err
+	}
+
+	return models.GrantsAll(held, permissions), nil
+}
+
+// CanManageUser signed in user holds every permission of user,
+// nobody manages a user with more permissions than they have
+func (s *service) CanManageUser(c *context.Context, userID uint) (bool, error) {
+	permissions, err := s.UserPermissions(c, userID)
+	if err != nil {
+		return false, err
+	}
+
+	return s.CanGrant(c, permissions)
+}
+
+// ClearUserRoles clear cached roles of user after they changed
+func (s *service) ClearUserRoles(userID uint) error {
+	return redis.GetConnection().Delete(fmt.Sprintf("%s%d", userRolesKeyPrefix, userID))
+}
+
+// GetPermissions get permissions known by authen service
+func (s *service) GetPermissions(_ *context.Context) ([]string, error) {
+	return models.Permissions, nil
+}
+
+// GetRoles get roles
+func (s *service) GetRoles(c *context.Context) ([]models.Role, error) {
+	roles := []models.Role{}
+	if err := s.roleRepository.FindAll(c.GetDatabase().Order("id"), &roles); err != nil {
+		logrus.Errorf("find roles error: %s", err)
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// CreateRole create role
+func (s *service) CreateRole(c *context.Context, request *request.RoleRequest) (*models.Role, error) {
+	db := c.GetDatabase()
+	if _, err := s.roleRepository.FindByName(db, request.Name); err == nil {
+		return nil, s.result.RoleAlreadyExists
+	}
+
+	if err := s.checkGrantable(c, request.Permissions); err != nil {
+		return nil, err
+	}
+
+	role := &models.Role{
+		Name:        request.Name,
+		Description: request.Description,
+		Permissions: models.StringArray(request.Permissions),
+	}
+
+	if role.Permissions == nil {
+		role.Permissions = models.StringArray{}
+	}
+
+	if err := s.roleRepository.Create(db, role); err != nil {
+		logrus.Errorf("create role error: %s", err)
+		return nil, err
+	}
+
+	return role, nil
+}
+
+// UpdateRole update role, roles of user roles can not be renamed,
+// only a user holding every old and new permission of role may change it
+func (s *service) UpdateRole(c *context.Context, request *request.RoleRequest) (*models.Role, error) {
+	db := c.GetDatabase()
+	role := &models.Role{}
+	if err := s.roleRepository.FindOneObjectByIDUInt(db, request.ID, role); err != nil {
+		logrus.Errorf("find role id=%d error: %s", request.ID, err)
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	if request.Name != role.Name {
+		if role.System {
+			return nil, s.result.Internal.BadRequest
+		}
+
+		if _, err := s.roleRepository.FindByName(db, request.Name); err == nil {
+			return nil, s.result.RoleAlreadyExists
+		}
+	}
+
+	if err := s.checkGrantable(c, append(append([]string{}, role.Permissions...), request.Permissions...)); err != nil {
+		return nil, err
+	}
+
+	name := role.Name
+	role.Name = request.Name
+	role.Description = request.Description
+	role.Permissions = models.StringArray(request.Permissions)
+	if role.Permissions == nil {
+		role.Permissions = models.StringArray{}
+	}
+
+	if err := s.roleRepository.Update(db, role); err != nil {
+		logrus.Errorf("update role error: %s", err)
+		return nil, err
+	}
+
+	_ = redis.GetConnection().Delete(rolePermissionsKeyPrefix + name)
+	if name != role.Name {
+		userIDs, err := s.roleRepository.FindRoleUserIDs(db, role.ID)
+		if err != nil {
+			logrus.Errorf("find users of role id=%d error: %s", role.ID, err)
+			return role, nil
+		}
+
+		s.clearUsersRoles(userIDs)
+	}
+
+	return role, nil
+}
+
+// DeleteRole delete role and unassign it from users, roles of user roles can not be deleted
+func (s *service) DeleteRole(c *context.Context, request *request.GetOne) error {
+	db := c.GetDatabase()
+	role := &models.Role{}
+	if err := s.roleRepository.FindOneObjectByIDUInt(db, request.ID, role); err != nil {
+		logrus.Errorf("find role id=%d error: %s", request.ID, err)
+		return s.result.Internal.DatabaseNotFound
+	}
+
+	if role.System {
+		return s.result.Internal.BadRequest
+	}
+
+	userIDs, err := s.roleRepository.FindRoleUserIDs(db, role.ID)
+	if err != nil {
+		logrus.Errorf("find users of role id=%d error: %s", role.ID, err)
+		return err
+	}
+
+	if err := s.roleRepository.DeleteRoleAssignments(db, role.ID); err != nil {
+		logrus.Errorf("delete assignments of role id=%d error: %s", role.ID, err)
+		return err
+	}
+
+	if err := s.roleRepository.HardDelete(db, role); err != nil {
+		logrus.Errorf("delete role id=%d error: %s", role.ID, err)
+		return err
+	}
+
+	_ = redis.GetConnection().Delete(rolePermissionsKeyPrefix + role.Name)
+	s.clearUsersRoles(userIDs)
+	return nil
+}
+
+// GetUserRoles get roles of user
+func (s *service) GetUserRoles(c *context.Context, request *request.GetOne) ([]models.Role, error) {
+	db := c.GetDatabase()
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(db, request.ID, user); err != nil {
+		logrus.Errorf("find user id=%d error: %s", request.ID, err)
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	roles, err := s.roleRepository.FindUserRoles(db, user.ID, user.Role)
+	if err != nil {
+		logrus.Errorf("find roles of user id=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	return roles, nil
+}
+
+// UpdateUserRoles replace roles assigned to user, role of user role is always kept,
+// nobody changes their own roles or gives roles with permissions they do not hold
+func (s *service) UpdateUserRoles(c *context.Context, request *request.UserRolesRequest) ([]models.Role, error) {
+	if request.UserID == c.GetUserID() {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	db := c.GetDatabase()
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(db, request.UserID, user); err != nil {
+		logrus.Errorf("find user id=%d error: %s", request.UserID, err)
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	roles := []models.Role{}
+	if len(request.RoleIDs) > 0 {
+		if err := s.roleRepository.FindAllByUintIDs(db, request.RoleIDs, &roles); err != nil {
+			logrus.Errorf("find roles error: %s", err)
+			return nil, err
+		}
+
+		if len(roles) != len(uniqueIDs(request.RoleIDs)) {
+			return nil, s.result.Internal.DatabaseNotFound
+		}
+	}
+
+	ok, err := s.CanManageUser(c, user.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !ok {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	permissions := []string{}
+	for _, role := range roles {
+		permissions = append(permissions, role.Permissions...)
+	}
+
+	if err := s.checkGrantable(c, permissions); err != nil {
+		return nil, err
+	}
+
+	if err := s.roleRepository.ReplaceUserRoles(db, user.ID, uniqueIDs(request.RoleIDs)); err != nil {
+		logrus.Errorf("replace roles of user id=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	if err := s.ClearUserRoles(user.ID); err != nil {
+		logrus.Errorf("clear cached roles of user id=%d error: %s", user.ID, err)
+	}
+
+	return s.roleRepository.FindUserRoles(db, user.ID, user.Role)
+}
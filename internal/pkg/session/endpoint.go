@@ -0,0 +1,170 @@
+// Package session is a user session package
+package session
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/handlers"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	Logout(c *fiber.Ctx) error
+	GetMySessions(c *fiber.Ctx) error
+	RevokeMySession(c *fiber.Ctx) error
+	RevokeMyOtherSessions(c *fiber.Ctx) error
+	GetUserSessions(c *fiber.Ctx) error
+	RevokeUserSession(c *fiber.Ctx) error
+	RevokeUserSessions(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config       *config.Configs
+	result       *config.ReturnResult
+	service      Service
+	tokenService token.Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:       config.CF,
+		result:       config.RR,
+		service:      NewService(),
+		tokenService: token.NewService(),
+	}
+}
+
+// Logout logout
+// @Tags Session
+// @Summary Logout
+// @Description Revoke current access token and its paired refresh token
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /logout [post]
+func (ep *endpoint) Logout(c *fiber.Ctx) error {
+	return handlers.ResponseSuccessWithoutRequest(c, ep.tokenService.Logout)
+}
+
+// GetMySessions get my sessions
+// @Tags Session
+// @Summary GetMySessions
+// @Description List sessions of current user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {array} models.Session
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/sessions [get]
+func (ep *endpoint) GetMySessions(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.GetMySessions)
+}
+
+// RevokeMySession revoke my session
+// @Tags Session
+// @Summary RevokeMySession
+// @Description Revoke one session of current user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path string true "session id"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/sessions/{id} [delete]
+func (ep *endpoint) RevokeMySession(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.RevokeMySession, &request.GetOneString{})
+}
+
+// RevokeMyOtherSessions revoke my other sessions
+// @Tags Session
+// @Summary RevokeMyOtherSessions
+// @Description Sign out other devices, revoke every session of current user except the current one
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/sessions/revoke-others [post]
+func (ep *endpoint) RevokeMyOtherSessions(c *fiber.Ctx) error {
+	return handlers.ResponseSuccessWithoutRequest(c, ep.service.RevokeMyOtherSessions)
+}
+
+// GetUserSessions get user sessions
+// @Tags Admin
+// @Summary GetUserSessions
+// @Description List sessions of user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Success 200 {array} models.Session
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/sessions [get]
+func (ep *endpoint) GetUserSessions(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.GetUserSessions, &request.GetOne{})
+}
+
+// RevokeUserSession revoke user session
+// @Tags Admin
+// @Summary RevokeUserSession
+// @Description Revoke one session of user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Param session_id path string true "session id"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/sessions/{session_id} [delete]
+func (ep *endpoint) RevokeUserSession(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.RevokeUserSession, &request.UserSessionRequest{})
+}
+
+// RevokeUserSessions revoke user sessions
+// @Tags Admin
+// @Summary RevokeUserSessions
+// @Description Revoke every session of user
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/sessions [delete]
+func (ep *endpoint) RevokeUserSessions(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.RevokeUserSessions, &request.GetOne{})
+}
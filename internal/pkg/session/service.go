@@ -0,0 +1,74 @@
+package session
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/request"
+)
+
+// Service service interface
+type Service interface {
+	GetMySessions(c *context.Context) ([]models.Session, error)
+	RevokeMySession(c *context.Context, request *request.GetOneString) error
+	RevokeMyOtherSessions(c *context.Context) error
+	GetUserSessions(c *context.Context, request *request.GetOne) ([]models.Session, error)
+	RevokeUserSession(c *context.Context, request *request.UserSessionRequest) error
+	RevokeUserSessions(c *context.Context, request *request.GetOne) error
+}
+
+type service struct {
+	config       *config.Configs
+	result       *config.ReturnResult
+	tokenService token.Service
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config:       config.CF,
+		result:       config.RR,
+		tokenService: token.NewService(),
+	}
+}
+
+// GetMySessions get sessions of current user
+func (s *service) GetMySessions(c *context.Context) ([]models.Session, error) {
+	sessions, err := s.tokenService.Sessions(c, c.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+
+	currentSessionID := c.GetClaims().SessionID
+	for i := range sessions {
+		sessions[i].Current = sessions[i].ID == currentSessionID
+	}
+
+	return sessions, nil
+}
+
+// RevokeMySession revoke one session of current user
+func (s *service) RevokeMySession(c *context.Context, request *request.GetOneString) error {
+	return s.tokenService.RevokeSession(c, c.GetUserID(), request.ID)
+}
+
+// RevokeMyOtherSessions sign out other devices
+func (s *service) RevokeMyOtherSessions(c *context.Context) error {
+	return s.tokenService.RevokeSessions(c, c.GetUserID(), c.GetClaims().SessionID)
+}
+
+// GetUserSessions get sessions of user (admin)
+func (s *service) GetUserSessions(c *context.Context, request *request.GetOne) ([]models.Session, error) {
+	return s.tokenService.Sessions(c, request.ID)
+}
+
+// RevokeUserSession revoke one session of user (admin)
+func (s *service) RevokeUserSession(c *context.Context, request *request.UserSessionRequest) error {
+	return s.tokenService.RevokeSession(c, request.UserID, request.SessionID)
+}
+
+// RevokeUserSessions revoke every session of user (admin)
+func (s *service) RevokeUserSessions(c *context.Context, request *request.GetOne) error {
+	return s.tokenService.RevokeSessions(c, request.ID, "")
+}
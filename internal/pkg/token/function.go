@@ -2,60 +2,171 @@
 package token
 
 import (
-	"crypto/sha256"
+	"crypto/rand"
 	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/unique"
 	"ecommerce-authen/internal/models"
-	"encoding/base64"
 	"fmt"
+	"hash/crc32"
+	"math/big"
 	"strings"
 	"time"
 
-	"github.com/golang-jwt/jwt/v4"
-	"github.com/labstack/gommon/random"
 	"github.com/sirupsen/logrus"
 )
 
-func generateRefreshToken(userID string) string {
-	hasher := sha256.New()
-	_, _ = hasher.Write([]byte(fmt.Sprintf("%s_%s_%s", userID, time.Now().String(), random.String(4))))
-	sha := base64.URLEncoding.EncodeToString(hasher.Sum(nil))
-	return strings.ToLower(sha)
+const (
+	// refreshTokenPrefix prefix of refresh tokens, lets secret scanners detect leaked tokens
+	refreshTokenPrefix = "eca_rt_"
+	// refreshTokenEntropySize bytes of random refresh token
+	refreshTokenEntropySize = 32
+	// refreshTokenEntropyLength base62 length of refresh token entropy
+	refreshTokenEntropyLength = 43
+	// refreshTokenChecksumLength base62 length of crc32 checksum
+	refreshTokenChecksumLength = 6
+
+	base62Characters = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+)
+
+// generateRefreshToken random refresh token: prefix, 256-bit entropy and crc32 checksum of both in base62
+func generateRefreshToken() (string, error) {
+	b := make([]byte, refreshTokenEntropySize)
+	if _, err := rand.Read(b); err != nil {
+		return "", err
+	}
+
+	payload := refreshTokenPrefix + base62(new(big.Int).SetBytes(b), refreshTokenEntropyLength)
+	return payload + refreshTokenChecksum(payload), nil
 }
 
-func (s *service) generateAccessToken(i interface{}) (*models.RefreshToken, error) {
-	var userID uint
-	var role models.UserRole
-	if u, ok := i.(*models.User); ok {
-		userID = u.ID
-		role = u.Role
-	} else if t, ok := i.(*models.RefreshToken); ok {
-		userID = t.UserID
-		role = t.Role
+// validRefreshToken refresh token has the prefix, length and checksum of generated tokens
+func validRefreshToken(refreshToken string) bool {
+	if len(refreshToken) != len(refreshTokenPrefix)+refreshTokenEntropyLength+refreshTokenChecksumLength ||
+		!strings.HasPrefix(refreshToken, refreshTokenPrefix) {
+		return false
 	}
 
+	for _, r := range refreshToken[len(refreshTokenPrefix):] {
+		if !strings.ContainsRune(base62Characters, r) {
+			return false
+		}
+	}
+
+	payload := refreshToken[:len(refreshToken)-refreshTokenChecksumLength]
+	return refreshTokenChecksum(payload) == refreshToken[len(payload):]
+}
+
+// refreshTokenChecksum crc32 of payload in base62
+func refreshTokenChecksum(payload string) string {
+	sum := crc32.ChecksumIEEE([]byte(payload))
+	return base62(new(big.Int).SetUint64(uint64(sum)), refreshTokenChecksumLength)
+}
+
+// base62 encode number in base62, left padded with zero to length
+func base62(n *big.Int, length int) string {
+	b := make([]byte, length)
+	base := big.NewInt(int64(len(base62Characters)))
+	mod := new(big.Int)
+	n = new(big.Int).Set(n)
+	for i := length - 1; i >= 0; i-- {
+		n.DivMod(n, base, mod)
+		b[i] = base62Characters[mod.Int64()]
+	}
+
+	return string(b)
+}
+
+func (s *service) generateAccessToken(u *models.User, family *tokenFamily) (*models.RefreshToken, error) {
 	now := time.Now()
 	c := &context.Claims{
-		Role: role,
+		Role:        u.Role,
+		SessionID:   family.ID,
+		Scope:       family.Scope,
+		ClientID:    family.ClientID,
+		AuthMethods: family.AuthMethods,
+		AuthTime:    family.AuthTime.Unix(),
+	}
+
+	// families created before auth time was kept
+	if family.AuthTime.IsZero() {
+		c.AuthMethods = family.LoginType.AuthMethods()
+		c.AuthTime = family.CreatedAt.Unix()
+	}
+
+	if u.Email != "" {
+		verified := u.EmailVerified()
+		c.EmailVerified = &verified
 	}
 
-	c.Subject = fmt.Sprintf("%d", userID)
+	c.Id = unique.UUID()
+	c.Issuer = s.config.OAuth.Issuer
+	c.Audience = family.Audience
+	c.Subject = fmt.Sprintf("%d", u.ID)
 	c.IssuedAt = now.Unix()
 	c.ExpiresAt = now.Add(s.config.JWT.ExpireTime).Unix()
-	token := jwt.NewWithClaims(jwt.SigningMethodHS256, c)
-	t, err := token.SignedString([]byte(s.config.JWT.Secret))
+	if family.ActorID != 0 {
+		c.Actor = &models.Actor{Subject: fmt.Sprintf("%d", family.ActorID)}
+		c.ExpiresAt = now.Add(s.config.JWT.ImpersonateExpireTime).Unix()
+	}
+
+	t, err := ring.signer().sign(c)
 	if err != nil {
 		logrus.Errorf("[generateAccessToken] signed string error:%s", err)
 		return nil, err
 	}
 
+	// impersonation tokens can not be renewed
+	if family.ActorID != 0 {
+		return &models.RefreshToken{
+			UserID:    u.ID,
+			SessionID: family.ID,
+			JWTToken:  t,
+			Role:      u.Role,
+			Scope:     family.Scope,
+		}, nil
+	}
+
+	refreshToken, err := generateRefreshToken()
+	if err != nil {
+		logrus.Errorf("[generateAccessToken] generate refresh token error:%s", err)
+		return nil, err
+	}
+
 	refreshTokenExpireTime := now.Add(s.config.JWT.RefreshTokenExpireTime)
 	accessToken := &models.RefreshToken{
-		UserID:       userID,
+		UserID:       u.ID,
+		SessionID:    family.ID,
 		JWTToken:     t,
-		RefreshToken: generateRefreshToken(fmt.Sprintf("%d", userID)),
+		RefreshToken: refreshToken,
 		ExpiredAt:    &refreshTokenExpireTime,
-		Role:         role,
+		Role:         u.Role,
+		Scope:        family.Scope,
 	}
 
 	return accessToken, nil
 }
+
+func (s *service) generateServiceToken(clientID, audience, scope string) (*models.RefreshToken, error) {
+	now := time.Now()
+	c := &context.Claims{
+		Scope:    scope,
+		ClientID: clientID,
+	}
+
+	c.Id = unique.UUID()
+	c.Issuer = s.config.OAuth.Issuer
+	c.Subject = clientID
+	c.Audience = audience
+	c.IssuedAt = now.Unix()
+	c.ExpiresAt = now.Add(s.config.JWT.ServiceTokenExpireTime).Unix()
+	t, err := ring.signer().sign(c)
+	if err != nil {
+		logrus.Errorf("[generateServiceToken] signed string error:%s", err)
+		return nil, err
+	}
+
+	return &models.RefreshToken{
+		JWTToken: t,
+		Scope:    scope,
+	}, nil
+}
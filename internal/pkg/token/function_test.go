@@ -0,0 +1,42 @@
+package token
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidRefreshToken(t *testing.T) {
+	generated, err := generateRefreshToken()
+	require.NoError(t, err)
+
+	payload := generated[:len(generated)-refreshTokenChecksumLength]
+	checksum := generated[len(payload):]
+	otherChecksum := strings.Repeat("0", refreshTokenChecksumLength)
+	if checksum == otherChecksum {
+		otherChecksum = strings.Repeat("1", refreshTokenChecksumLength)
+	}
+
+	tests := []struct {
+		name         string
+		refreshToken string
+		want         bool
+	}{
+		{name: "generated", refreshToken: generated, want: true},
+		{name: "empty", refreshToken: "", want: false},
+		{name: "wrong checksum", refreshToken: payload + otherChecksum, want: false},
+		{name: "other prefix", refreshToken: "xyz_rt_" + generated[len(refreshTokenPrefix):], want: false},
+		{name: "too short", refreshToken: generated[:len(generated)-1], want: false},
+		{name: "too long", refreshToken: generated + "0", want: false},
+		{name: "not base62", refreshToken: refreshTokenPrefix + "-" + generated[len(refreshTokenPrefix)+1:], want: false},
+		{name: "legacy jwt", refreshToken: "eyJhbGciOiJIUzI1NiJ9.e30.signature", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, validRefreshToken(tt.refreshToken))
+		})
+	}
+}
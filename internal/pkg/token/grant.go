@@ -0,0 +1,22 @@
+package token
+
+import (
+	"ecommerce-authen/internal/models"
+	"time"
+)
+
+// Grant how tokens were granted, the grant is kept with the token family
+// so renewed tokens carry the same client, scope and authentication
+type Grant struct {
+	LoginType models.LoginType
+	ClientID  string
+	Scope     string
+
+	// AuthMethods authentication methods, derived from login type when empty
+	AuthMethods []string
+	// AuthTime time user authenticated, token family creation when zero
+	AuthTime time.Time
+	// ActorID admin impersonating user, impersonation tokens are short-lived
+	// and have no refresh token
+	ActorID uint
+}
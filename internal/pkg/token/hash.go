@@ -0,0 +1,43 @@
+package token
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"ecommerce-authen/internal/core/config"
+	"encoding/hex"
+)
+
+const (
+	accessTokenKeyPrefix  = "access_token:"
+	refreshTokenKeyPrefix = "refresh_token:"
+	// verifiedTokenKeyPrefix identity of verified access token cached for forward auth
+	verifiedTokenKeyPrefix = "verified_token:"
+)
+
+// Hash keyed hash (HMAC-SHA256) of token, tokens are only stored by their hash
+// so reading redis does not give live credentials
+func Hash(token string) string {
+	secret := config.CF.JWT.TokenHashSecret
+	if secret == "" {
+		secret = config.CF.App.SecretKey
+	}
+
+	mac := hmac.New(sha256.New, []byte(secret))
+	_, _ = mac.Write([]byte(token))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// accessTokenKey redis key of access token hash
+func accessTokenKey(hash string) string {
+	return accessTokenKeyPrefix + hash
+}
+
+// refreshTokenKey redis key of refresh token hash
+func refreshTokenKey(hash string) string {
+	return refreshTokenKeyPrefix + hash
+}
+
+// verifiedTokenKey redis key of identity of verified access token hash
+func verifiedTokenKey(hash string) string {
+	return verifiedTokenKeyPrefix + hash
+}
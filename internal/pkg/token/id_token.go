@@ -0,0 +1,61 @@
+package token
+
+import (
+	"ecommerce-authen/internal/models"
+	"errors"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+// errSymmetricIDToken id tokens are verified by clients with published keys
+var errSymmetricIDToken = errors.New("id token needs an ES256 or RS256 jwt signing key, active key is HS256")
+
+// idTokenClaims id token claims (OpenID Connect Core 1.0 section 2)
+type idTokenClaims struct {
+	jwt.StandardClaims
+	AuthTime      int64  `json:"auth_time,omitempty"`
+	Nonce         string `json:"nonce,omitempty"`
+	Email         string `json:"email,omitempty"`
+	EmailVerified *bool  `json:"email_verified,omitempty"`
+	PhoneNumber   string `json:"phone_number,omitempty"`
+	Name          string `json:"name,omitempty"`
+	Picture       string `json:"picture,omitempty"`
+}
+
+// CreateIDToken create id token of user for client, only asymmetric keys sign id tokens
+func (s *service) CreateIDToken(clientID, nonce string, authTime time.Time, info *models.UserInfo) (string, error) {
+	k := ring.signer()
+	if k.symmetric() {
+		logrus.Errorf("[CreateIDToken] %s", errSymmetricIDToken)
+		return "", errSymmetricIDToken
+	}
+
+	now := time.Now()
+	c := &idTokenClaims{
+		Nonce:         nonce,
+		Email:         info.Email,
+		EmailVerified: info.EmailVerified,
+		PhoneNumber:   info.PhoneNumber,
+		Name:          info.Name,
+		Picture:       info.Picture,
+	}
+
+	if !authTime.IsZero() {
+		c.AuthTime = authTime.Unix()
+	}
+
+	c.Issuer = s.config.OAuth.Issuer
+	c.Subject = info.Sub
+	c.Audience = clientID
+	c.IssuedAt = now.Unix()
+	c.ExpiresAt = now.Add(s.config.JWT.ExpireTime).Unix()
+	t, err := k.sign(c)
+	if err != nil {
+		logrus.Errorf("[CreateIDToken] signed string error:%s", err)
+		return "", err
+	}
+
+	return t, nil
+}
@@ -0,0 +1,240 @@
+package token
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ring = &keyRing{keys: map[string]*signingKey{}, retired: map[string]time.Time{}}
+)
+
+// keyRing signing keys, one active key signs new tokens and every other
+// key is only used for verification. Keys removed from configuration are
+// kept until tokens signed with them have expired.
+type keyRing struct {
+	mutex   sync.RWMutex
+	active  *signingKey
+	keys    map[string]*signingKey
+	retired map[string]time.Time
+}
+
+// InitKeyRing load signing keys from configuration
+// and rotate them again every time the config file changed
+func InitKeyRing(cf *config.Configs) error {
+	if err := ring.reload(cf); err != nil {
+		return err
+	}
+
+	config.OnReload(func(cf *config.Configs) {
+		if err := ring.reload(cf); err != nil {
+			logrus.Errorf("[InitKeyRing] reload signing keys error, keep current keys: %s", err)
+		}
+	})
+
+	return nil
+}
+
+// reload replace configured keys, current keys are kept when any key is invalid
+func (r *keyRing) reload(cf *config.Configs) error {
+	keyConfigs := cf.JWT.Keys
+	if len(keyConfigs) == 0 {
+		keyConfigs = []config.SigningKeyConfig{{SigningMethod: cf.JWT.SigningMethod}}
+	}
+
+	keys := map[string]*signingKey{}
+	for _, kc := range keyConfigs {
+		if kc.SigningMethod == "" {
+			kc.SigningMethod = cf.JWT.SigningMethod
+		}
+
+		k, err := loadSigningKey(kc, cf.JWT.Secret)
+		if err != nil {
+			return err
+		}
+
+		if _, ok := keys[k.id]; ok {
+			return fmt.Errorf("duplicate jwt key id=%s", k.id)
+		}
+
+		keys[k.id] = k
+	}
+
+	activeID := cf.JWT.ActiveKeyID
+	if activeID == "" && len(keyConfigs) == 1 {
+		for id := range keys {
+			activeID = id
+		}
+	}
+
+	active, ok := keys[activeID]
+	if !ok {
+		return fmt.Errorf("active jwt key id=%s not found", activeID)
+	}
+
+	if !active.canSign() {
+		return fmt.Errorf("active jwt key id=%s has no private key", activeID)
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	now := time.Now()
+	for id, k := range r.keys {
+		if _, ok := keys[id]; ok {
+			continue
+		}
+
+		retiredAt, ok := r.retired[id]
+		if !ok {
+			retiredAt = now
+			logrus.Infof("[keyRing] retire jwt key id=%s", id)
+		}
+
+		if now.Sub(retiredAt) < keyRetentionTime(cf) {
+			keys[id] = k
+			r.retired[id] = retiredAt
+		}
+	}
+
+	for id := range r.retired {
+		if _, ok := keys[id]; !ok || keys[id] != r.keys[id] {
+			delete(r.retired, id)
+		}
+	}
+
+	if r.active == nil || r.active.id != active.id {
+		logrus.Infof("[keyRing] active jwt key id=%s", active.id)
+	}
+
+	r.active = active
+	r.keys = keys
+	return nil
+}
+
+// signer active signing key
+func (r *keyRing) signer() *signingKey {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return r.active
+}
+
+// verifier key for verify token by key id
+func (r *keyRing) verifier(kid string) (*signingKey, bool) {
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	k, ok := r.keys[kid]
+	if !ok || r.expired(kid, time.Now()) {
+		return nil, false
+	}
+
+	return k, true
+}
+
+// expired key was retired longer ago than any token signed with it lives,
+// callers hold the lock
+func (r *keyRing) expired(id string, now time.Time) bool {
+	retiredAt, ok := r.retired[id]
+	return ok && now.Sub(retiredAt) >= keyRetentionTime(config.CF)
+}
+
+// keyRetentionTime how long a retired key is kept, the longest lifetime of
+// tokens it signed: access and id tokens, impersonation and service tokens.
+// Refresh tokens are opaque and not signed.
+func keyRetentionTime(cf *config.Configs) time.Duration {
+	retention := cf.JWT.ExpireTime
+	for _, d := range []time.Duration{cf.JWT.ImpersonateExpireTime, cf.JWT.ServiceTokenExpireTime} {
+		if d > retention {
+			retention = d
+		}
+	}
+
+	return retention
+}
+
+// keyFunc resolve verification key for token by `kid` header
+func keyFunc(t *jwt.Token) (interface{}, error) {
+	kid, _ := t.Header["kid"].(string)
+	k, ok := ring.verifier(kid)
+	if !ok {
+		return nil, fmt.Errorf("unexpected jwt key id=%v", t.Header["kid"])
+	}
+
+	if t.Method.Alg() != k.method.Alg() {
+		return nil, fmt.Errorf("unexpected jwt signing method=%v", t.Header["alg"])
+	}
+
+	return k.publicKey, nil
+}
+
+// ParseAccessToken parse and verify access token,
+// tokens without issuer were signed before issuer was added and are accepted
+func ParseAccessToken(accessToken string) (*jwt.Token, error) {
+	t, err := jwt.ParseWithClaims(accessToken, &context.Claims{}, keyFunc)
+	if err != nil {
+		return t, err
+	}
+
+	if !t.Claims.(*context.Claims).VerifyIssuer(config.CF.OAuth.Issuer, false) {
+		t.Valid = false
+		return t, fmt.Errorf("unexpected jwt issuer=%s", t.Claims.(*context.Claims).Issuer)
+	}
+
+	return t, nil
+}
+
+// VerifyAudience token is for application of request source,
+// tokens of oauth clients are for the client and tokens without audience are for every application
+func VerifyAudience(claims *context.Claims, source string) bool {
+	if claims.Audience == "" || claims.ClientID != "" {
+		return true
+	}
+
+	return claims.Audience == source
+}
+
+// SigningAlgorithm algorithm of active signing key
+func SigningAlgorithm() string {
+	return ring.signer().method.Alg()
+}
+
+// IDTokenSupported active signing key can sign id tokens, which clients
+// verify with published keys so HS256 shared secret can not be used
+func IDTokenSupported() bool {
+	return !ring.signer().symmetric()
+}
+
+// PublicKeys public keys for verify access token,
+// inactive keys are published too so tokens signed before a rotation stay verifiable
+// until they have expired
+func PublicKeys() *models.JWKSet {
+	ring.mutex.RLock()
+	defer ring.mutex.RUnlock()
+
+	now := time.Now()
+	set := &models.JWKSet{Keys: []models.JWK{}}
+	for id, k := range ring.keys {
+		if k.symmetric() || ring.expired(id, now) {
+			continue
+		}
+
+		if j := k.jwk(); j != nil {
+			set.Keys = append(set.Keys, *j)
+		}
+	}
+
+	sort.Slice(set.Keys, func(i, j int) bool {
+		return set.Keys[i].KeyID < set.Keys[j].KeyID
+	})
+
+	return set
+}
@@ -1,20 +1,35 @@
 package token
 
 import (
+	"ecommerce-authen/internal/core/audit"
 	"ecommerce-authen/internal/core/config"
 	"ecommerce-authen/internal/core/context"
 	"ecommerce-authen/internal/core/redis"
 	"ecommerce-authen/internal/models"
 	"ecommerce-authen/internal/repositories"
 	"ecommerce-authen/internal/request"
+	"sort"
+	"time"
 
+	"github.com/gofiber/fiber/v2"
 	"github.com/sirupsen/logrus"
 )
 
 // Service service interface
 type Service interface {
-	Create(c *context.Context, u *models.User) (*models.RefreshToken, error)
+	Create(c *context.Context, u *models.User, grant Grant) (*models.RefreshToken, error)
 	RenewToken(c *context.Context, f *request.RefreshTokenRequest) (*models.RefreshToken, error)
+	RenewClientToken(c *context.Context, clientID, refreshToken string) (*models.RefreshToken, error)
+	CreateServiceToken(c *context.Context, clientID, audience, scope string) (*models.RefreshToken, error)
+	CreateIDToken(clientID, nonce string, authTime time.Time, info *models.UserInfo) (string, error)
+	Logout(c *context.Context) error
+	Revoke(c *context.Context, f *request.RevokeTokenRequest) error
+	Introspect(c *context.Context, token, tokenTypeHint string) (*models.Introspection, error)
+	Sessions(c *context.Context, userID uint) ([]models.Session, error)
+	Session(c *context.Context, userID uint, sessionID string) (*models.Session, error)
+	RevokeSession(c *context.Context, userID uint, sessionID string) error
+	RevokeSessions(c *context.Context, userID uint, exceptSessionID string) error
+	Verify(c *context.Context, accessToken string) (*models.Identity, error)
 }
 
 type service struct {
@@ -32,67 +47,251 @@ func NewService() Service {
 	}
 }
 
-// Create create token
-func (s *service) Create(c *context.Context, u *models.User) (*models.RefreshToken, error) {
-	a, err := s.generateAccessToken(u)
+// Create create token, every login starts a new token family
+func (s *service) Create(c *context.Context, u *models.User, grant Grant) (*models.RefreshToken, error) {
+	family := newTokenFamily(c, u.ID, grant)
+	a, err := s.generateAccessToken(u, family)
 	if err != nil {
 		return nil, err
 	}
 
-	conn := redis.GetConnection()
-	err = conn.Set(a.JWTToken, u.ID, s.config.JWT.ExpireTime)
+	err = s.storeTokens(family, a)
 	if err != nil {
-		logrus.Errorf("set jwt token error: %s", err)
 		return nil, err
 	}
 
-	err = conn.Set(a.RefreshToken, u.ID, s.config.JWT.RefreshTokenExpireTime)
+	return a, nil
+}
+
+// RenewToken renew token of first-party apps
+func (s *service) RenewToken(c *context.Context, f *request.RefreshTokenRequest) (*models.RefreshToken, error) {
+	return s.renew(c, f.RefreshToken, "")
+}
+
+// CreateServiceToken create short-lived access token of client itself,
+// service tokens have no refresh token and no session
+func (s *service) CreateServiceToken(c *context.Context, clientID, audience, scope string) (*models.RefreshToken, error) {
+	a, err := s.generateServiceToken(clientID, audience, scope)
+	if err != nil {
+		return nil, err
+	}
+
+	err = redis.GetConnection().Set(accessTokenKey(Hash(a.JWTToken)), uint(0), s.config.JWT.ServiceTokenExpireTime)
 	if err != nil {
-		logrus.Errorf("set refresh token error: %s", err)
+		logrus.Errorf("set service token error: %s", err)
 		return nil, err
 	}
 
 	return a, nil
 }
 
-// RenewToken renew token
-func (s *service) RenewToken(c *context.Context, f *request.RefreshTokenRequest) (*models.RefreshToken, error) {
-	conn := redis.GetConnection()
-	var userID uint
-	err := conn.Get(f.RefreshToken, &userID)
+// RenewClientToken renew token issued to oauth client
+func (s *service) RenewClientToken(c *context.Context, clientID, refreshToken string) (*models.RefreshToken, error) {
+	return s.renew(c, refreshToken, clientID)
+}
+
+// renew renew token, the refresh token is claimed atomically before new tokens are issued
+// and presenting a refresh token that was already rotated revokes the whole token family
+func (s *service) renew(c *context.Context, refreshToken, clientID string) (*models.RefreshToken, error) {
+	record, err := s.findRefreshToken(refreshToken)
 	if err != nil {
+		if familyID, err := s.findRotatedRefreshToken(refreshToken); err == nil {
+			return nil, s.detectRefreshTokenReuse(c, familyID)
+		}
+
 		logrus.Errorf("get user id from refresh token error: %s", err)
+		return nil, s.result.InvalidToken
+	}
+
+	family := newTokenFamily(c, record.UserID, Grant{})
+	if record.FamilyID != "" {
+		family, err = s.findTokenFamily(record.FamilyID)
+		if err != nil || family.refreshTokenHash() != Hash(refreshToken) {
+			logrus.Errorf("find token family id=%s error: %v", record.FamilyID, err)
+			_ = redis.GetConnection().Delete(refreshTokenKey(Hash(refreshToken)))
+			return nil, s.result.InvalidToken
+		}
+	}
+
+	if family.ClientID != clientID || family.ActorID != 0 {
+		return nil, s.result.InvalidToken
+	}
+
+	// a concurrent renew with the same token already claimed it, handled like reuse
+	claimed, err := s.rotateRefreshToken(family, refreshToken)
+	if err != nil {
 		return nil, err
 	}
 
+	if !claimed {
+		return nil, s.detectRefreshTokenReuse(c, family.ID)
+	}
+
 	u := &models.User{}
-	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), userID, u); err != nil {
-		logrus.Errorf("find user by token userID=%d error:%s", userID, err)
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), record.UserID, u); err != nil {
+		logrus.Errorf("find user by token userID=%d error:%s", record.UserID, err)
 		return nil, s.result.Internal.DatabaseNotFound
 	}
 
-	a, err := s.generateAccessToken(u)
+	if u.Deactivated() {
+		return nil, s.result.BlockedUser
+	}
+
+	a, err := s.generateAccessToken(u, family)
 	if err != nil {
 		return nil, err
 	}
 
-	err = conn.Delete(f.RefreshToken)
+	// family revoked meanwhile by reuse of the same token must not be stored again
+	if record.FamilyID != "" {
+		if ok, err := redis.GetConnection().Exists(tokenFamilyKeyPrefix + family.ID); err == nil && !ok {
+			return nil, s.result.LoginAgain
+		}
+	}
+
+	family.IP = c.IP()
+	family.LastUsedAt = time.Now()
+
+	err = s.storeTokens(family, a)
 	if err != nil {
-		logrus.Errorf("delete refresh token in redis error: %s", err)
 		return nil, err
 	}
 
-	err = conn.Set(a.JWTToken, u.ID, s.config.JWT.ExpireTime)
+	return a, nil
+}
+
+// Logout revoke current access token and its paired refresh token
+func (s *service) Logout(c *context.Context) error {
+	sessionID := c.GetClaims().SessionID
+	if sessionID == "" {
+		_, err := s.revokeAccessToken(c.GetAccessToken())
+		return err
+	}
+
+	family, err := s.findTokenFamily(sessionID)
 	if err != nil {
-		logrus.Errorf("set jwt token error: %s", err)
-		return nil, err
+		_, err := s.revokeAccessToken(c.GetAccessToken())
+		return err
+	}
+
+	return s.revokeTokenFamily(family)
+}
+
+// Revoke revoke access token or refresh token (RFC 7009),
+// revoking a refresh token also revokes access tokens of the same family.
+// Invalid tokens do not cause an error response.
+func (s *service) Revoke(c *context.Context, f *request.RevokeTokenRequest) error {
+	if f.TokenTypeHint == models.TokenTypeHintRefreshToken {
+		if ok, err := s.revokeRefreshToken(f.Token); ok || err != nil {
+			return err
+		}
+
+		_, err := s.revokeAccessToken(f.Token)
+		return err
 	}
 
-	err = conn.Set(a.RefreshToken, u.ID, s.config.JWT.RefreshTokenExpireTime)
+	if ok, err := s.revokeAccessToken(f.Token); ok || err != nil {
+		return err
+	}
+
+	_, err := s.revokeRefreshToken(f.Token)
+	return err
+}
+
+// Introspect introspect access token or refresh token (RFC 7662),
+// revoked and expired tokens are inactive
+func (s *service) Introspect(c *context.Context, token, tokenTypeHint string) (*models.Introspection, error) {
+	if tokenTypeHint == models.TokenTypeHintRefreshToken {
+		if i := s.introspectRefreshToken(token); i.Active {
+			return i, nil
+		}
+
+		return s.introspectAccessToken(token), nil
+	}
+
+	if i := s.introspectAccessToken(token); i.Active {
+		return i, nil
+	}
+
+	return s.introspectRefreshToken(token), nil
+}
+
+// Sessions live sessions of user
+func (s *service) Sessions(c *context.Context, userID uint) ([]models.Session, error) {
+	families, err := s.findUserTokenFamilies(userID)
 	if err != nil {
-		logrus.Errorf("set refresh token error: %s", err)
 		return nil, err
 	}
 
-	return a, nil
+	sessions := []models.Session{}
+	for _, family := range families {
+		sessions = append(sessions, family.session())
+	}
+
+	sort.Slice(sessions, func(i, j int) bool {
+		return sessions[i].LastUsedAt.After(sessions[j].LastUsedAt)
+	})
+
+	return sessions, nil
+}
+
+// Session live session of user
+func (s *service) Session(c *context.Context, userID uint, sessionID string) (*models.Session, error) {
+	family, err := s.findTokenFamily(sessionID)
+	if err != nil || family.UserID != userID {
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	session := family.session()
+	return &session, nil
+}
+
+// RevokeSession revoke one session of user
+func (s *service) RevokeSession(c *context.Context, userID uint, sessionID string) error {
+	family, err := s.findTokenFamily(sessionID)
+	if err != nil || family.UserID != userID {
+		return s.result.Internal.DatabaseNotFound
+	}
+
+	return s.revokeTokenFamily(family)
+}
+
+// RevokeSessions revoke every session of user except exceptSessionID
+func (s *service) RevokeSessions(c *context.Context, userID uint, exceptSessionID string) error {
+	families, err := s.findUserTokenFamilies(userID)
+	if err != nil {
+		return err
+	}
+
+	for _, family := range families {
+		if family.ID == exceptSessionID {
+			continue
+		}
+
+		if err := s.revokeTokenFamily(family); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// detectRefreshTokenReuse revoke token family of a replayed refresh token
+func (s *service) detectRefreshTokenReuse(c *context.Context, familyID string) error {
+	fields := logrus.Fields{
+		"family_id":  familyID,
+		"ip":         c.IP(),
+		"user_agent": c.Get(fiber.HeaderUserAgent),
+	}
+
+	family, err := s.findTokenFamily(familyID)
+	if err == nil {
+		fields["user_id"] = family.UserID
+		if err := s.revokeTokenFamily(family); err != nil {
+			return err
+		}
+	}
+
+	audit.Security(audit.EventRefreshTokenReuse, fields)
+	return s.result.LoginAgain
 }
@@ -0,0 +1,186 @@
+package token
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/utils"
+	"ecommerce-authen/internal/models"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// keyUseSignature jwk "use" value for signing keys
+	keyUseSignature = "sig"
+)
+
+// signingKey key used to sign and verify access tokens
+type signingKey struct {
+	id         string
+	method     jwt.SigningMethod
+	privateKey interface{}
+	publicKey  interface{}
+}
+
+// loadSigningKey load signing key by signing method,
+// HS256 uses the shared secret and is kept for backward compatibility
+func loadSigningKey(kc config.SigningKeyConfig, secret string) (*signingKey, error) {
+	switch strings.ToUpper(kc.SigningMethod) {
+	case "", jwt.SigningMethodHS256.Alg():
+		return &signingKey{
+			id:         kc.ID,
+			method:     jwt.SigningMethodHS256,
+			privateKey: []byte(secret),
+			publicKey:  []byte(secret),
+		}, nil
+
+	case jwt.SigningMethodES256.Alg():
+		private, public, err := readECDSAKey(kc.PrivateKey, kc.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("read ecdsa key id=%s error: %w", kc.ID, err)
+		}
+
+		if public.Curve != elliptic.P256() {
+			return nil, fmt.Errorf("ES256 key id=%s requires a P-256 key", kc.ID)
+		}
+
+		k := &signingKey{id: kc.ID, method: jwt.SigningMethodES256, publicKey: public}
+		if private != nil {
+			k.privateKey = private
+		}
+
+		if k.id == "" {
+			k.id = k.thumbprint()
+		}
+
+		return k, nil
+
+	case jwt.SigningMethodRS256.Alg():
+		private, public, err := readRSAKey(kc.PrivateKey, kc.PublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("read rsa key id=%s error: %w", kc.ID, err)
+		}
+
+		k := &signingKey{id: kc.ID, method: jwt.SigningMethodRS256, publicKey: public}
+		if private != nil {
+			k.privateKey = private
+		}
+
+		if k.id == "" {
+			k.id = k.thumbprint()
+		}
+
+		return k, nil
+	}
+
+	return nil, fmt.Errorf("unsupported jwt signing method: %s", kc.SigningMethod)
+}
+
+// readECDSAKey read key pair, a key without private key is only used for verification
+func readECDSAKey(privateKey, publicKey string) (*ecdsa.PrivateKey, *ecdsa.PublicKey, error) {
+	if privateKey != "" {
+		return utils.ReadECDSAKey(privateKey, publicKey)
+	}
+
+	b, err := os.ReadFile(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	public, err := jwt.ParseECPublicKeyFromPEM(b)
+	return nil, public, err
+}
+
+// readRSAKey read key pair, a key without private key is only used for verification
+func readRSAKey(privateKey, publicKey string) (*rsa.PrivateKey, *rsa.PublicKey, error) {
+	if privateKey != "" {
+		return utils.ReadRSAKey(privateKey, publicKey)
+	}
+
+	b, err := os.ReadFile(publicKey)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	public, err := jwt.ParseRSAPublicKeyFromPEM(b)
+	return nil, public, err
+}
+
+// canSign key has private key
+func (k *signingKey) canSign() bool {
+	return k.privateKey != nil
+}
+
+// symmetric symmetric key must never be published
+func (k *signingKey) symmetric() bool {
+	_, ok := k.publicKey.([]byte)
+	return ok
+}
+
+// sign sign claims with key
+func (k *signingKey) sign(claims jwt.Claims) (string, error) {
+	token := jwt.NewWithClaims(k.method, claims)
+	if k.id != "" {
+		token.Header["kid"] = k.id
+	}
+
+	return token.SignedString(k.privateKey)
+}
+
+// jwk public key as json web key
+func (k *signingKey) jwk() *models.JWK {
+	switch public := k.publicKey.(type) {
+	case *ecdsa.PublicKey:
+		size := (public.Curve.Params().BitSize + 7) / 8
+		return &models.JWK{
+			KeyType:   "EC",
+			Use:       keyUseSignature,
+			KeyID:     k.id,
+			Algorithm: k.method.Alg(),
+			Curve:     public.Curve.Params().Name,
+			X:         base64.RawURLEncoding.EncodeToString(public.X.FillBytes(make([]byte, size))),
+			Y:         base64.RawURLEncoding.EncodeToString(public.Y.FillBytes(make([]byte, size))),
+		}
+
+	case *rsa.PublicKey:
+		return &models.JWK{
+			KeyType:   "RSA",
+			Use:       keyUseSignature,
+			KeyID:     k.id,
+			Algorithm: k.method.Alg(),
+			N:         base64.RawURLEncoding.EncodeToString(public.N.Bytes()),
+			E:         base64.RawURLEncoding.EncodeToString(big.NewInt(int64(public.E)).Bytes()),
+		}
+	}
+
+	return nil
+}
+
+// thumbprint jwk thumbprint (RFC 7638) used as key id
+func (k *signingKey) thumbprint() string {
+	j := k.jwk()
+	if j == nil {
+		return ""
+	}
+
+	// members must be in lexicographic order, json.Marshal sorts map keys
+	members := map[string]string{"kty": j.KeyType}
+	if j.KeyType == "EC" {
+		members["crv"], members["x"], members["y"] = j.Curve, j.X, j.Y
+	} else {
+		members["e"], members["n"] = j.E, j.N
+	}
+
+	b, _ := json.Marshal(members)
+	sum := sha256.Sum256(b)
+	return base64.RawURLEncoding.EncodeToString(sum[:])
+}
@@ -0,0 +1,490 @@
+package token
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/core/unique"
+	"ecommerce-authen/internal/core/utils"
+	"ecommerce-authen/internal/models"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	tokenFamilyKeyPrefix         = "token_family:"
+	rotatedRefreshTokenKeyPrefix = "rotated_refresh_token:"
+	userSessionsKeyPrefix        = "user_sessions:"
+)
+
+// refreshTokenRecord value stored with refresh token
+type refreshTokenRecord struct {
+	UserID   uint
+	FamilyID string
+}
+
+// tokenFamily tokens issued from the same login, every renew
+// rotates the refresh token but stays in the same family.
+// A token family is what users see as a session.
+type tokenFamily struct {
+	ID                string
+	UserID            uint
+	RefreshTokenHash  string
+	AccessTokenHashes []string
+	CreatedAt         time.Time
+	UserAgent         string
+	IP                string
+	LoginType         models.LoginType
+	LastUsedAt        time.Time
+	ClientID          string
+	Scope             string
+	Audience          string
+	AuthMethods       []string
+	AuthTime          time.Time
+	ActorID           uint
+
+	// RefreshToken and AccessTokens raw tokens of families stored before tokens were hashed
+	RefreshToken string
+	AccessTokens []string
+}
+
+// newTokenFamily new token family, tokens of oauth clients are for the client
+// and tokens of first-party apps are for the calling application
+func newTokenFamily(c *context.Context, userID uint, grant Grant) *tokenFamily {
+	now := time.Now()
+	family := &tokenFamily{
+		ID:          unique.UUID(),
+		UserID:      userID,
+		CreatedAt:   now,
+		UserAgent:   c.Get(fiber.HeaderUserAgent),
+		IP:          c.IP(),
+		LoginType:   grant.LoginType,
+		LastUsedAt:  now,
+		ClientID:    grant.ClientID,
+		Scope:       grant.Scope,
+		Audience:    grant.ClientID,
+		AuthMethods: grant.AuthMethods,
+		AuthTime:    grant.AuthTime,
+		ActorID:     grant.ActorID,
+	}
+
+	if family.Audience == "" && utils.ContainsString(config.CF.App.Sources, c.GetSource()) {
+		family.Audience = c.GetSource()
+	}
+
+	if len(family.AuthMethods) == 0 {
+		family.AuthMethods = grant.LoginType.AuthMethods()
+	}
+
+	if family.AuthTime.IsZero() {
+		family.AuthTime = now
+	}
+
+	return family
+}
+
+// session token family as session
+func (f *tokenFamily) session() models.Session {
+	return models.Session{
+		ID:          f.ID,
+		UserID:      f.UserID,
+		UserAgent:   f.UserAgent,
+		IP:          f.IP,
+		LoginType:   f.LoginType,
+		ClientID:    f.ClientID,
+		AuthMethods: f.AuthMethods,
+		ActorID:     f.ActorID,
+		CreatedAt:   f.CreatedAt,
+		LastUsedAt:  f.LastUsedAt,
+	}
+}
+
+// expireTime time family is kept, impersonation families have no refresh token
+// and end with their access token
+func (f *tokenFamily) expireTime() time.Duration {
+	if f.ActorID != 0 {
+		return config.CF.JWT.ImpersonateExpireTime
+	}
+
+	return config.CF.JWT.RefreshTokenExpireTime
+}
+
+// refreshTokenHash hash of current refresh token
+func (f *tokenFamily) refreshTokenHash() string {
+	if f.RefreshTokenHash == "" && f.RefreshToken != "" {
+		return Hash(f.RefreshToken)
+	}
+
+	return f.RefreshTokenHash
+}
+
+// legacyKey raw token may only be read as legacy key when it is not a namespaced key
+func legacyKey(token string) bool {
+	return config.CF.JWT.LegacyTokenKeys && token != "" && !strings.Contains(token, ":")
+}
+
+// migrateLegacyKey move value of raw token key to hashed key, keeping its expire
+func migrateLegacyKey(from, to string, value interface{}, expiredTime time.Duration) error {
+	conn := redis.GetConnection()
+	if ttl, err := conn.GetTTL(from); err == nil && ttl > 0 {
+		expiredTime = ttl
+	}
+
+	if err := conn.Set(to, value, expiredTime); err != nil {
+		logrus.Errorf("migrate legacy token key error: %s", err)
+		return err
+	}
+
+	return conn.Delete(from)
+}
+
+// FindAccessToken find user id of live access token
+func FindAccessToken(accessToken string) (uint, error) {
+	conn := redis.GetConnection()
+	key := accessTokenKey(Hash(accessToken))
+
+	var userID uint
+	err := conn.Get(key, &userID)
+	if err == nil {
+		return userID, nil
+	}
+
+	if !legacyKey(accessToken) {
+		return 0, err
+	}
+
+	if legacyErr := conn.Get(accessToken, &userID); legacyErr != nil {
+		return 0, err
+	}
+
+	_ = migrateLegacyKey(accessToken, key, userID, config.CF.JWT.ExpireTime)
+	return userID, nil
+}
+
+// findRefreshToken find refresh token record,
+// tokens issued before token families only stored the user id
+func (s *service) findRefreshToken(refreshToken string) (*refreshTokenRecord, error) {
+	if !validRefreshToken(refreshToken) && !legacyKey(refreshToken) {
+		return nil, s.result.InvalidToken
+	}
+
+	conn := redis.GetConnection()
+	key := refreshTokenKey(Hash(refreshToken))
+	record := &refreshTokenRecord{}
+	err := conn.Get(key, record)
+	if err == nil {
+		return record, nil
+	}
+
+	if !legacyKey(refreshToken) {
+		return nil, err
+	}
+
+	if legacyErr := conn.Get(refreshToken, record); legacyErr != nil {
+		var userID uint
+		if legacyErr := conn.Get(refreshToken, &userID); legacyErr != nil {
+			return nil, err
+		}
+
+		record = &refreshTokenRecord{UserID: userID}
+	}
+
+	_ = migrateLegacyKey(refreshToken, key, record, s.config.JWT.RefreshTokenExpireTime)
+	return record, nil
+}
+
+// findRotatedRefreshToken find token family of refresh token that was already rotated
+func (s *service) findRotatedRefreshToken(refreshToken string) (string, error) {
+	if !validRefreshToken(refreshToken) && !legacyKey(refreshToken) {
+		return "", s.result.InvalidToken
+	}
+
+	conn := redis.GetConnection()
+	var familyID string
+	err := conn.Get(rotatedRefreshTokenKeyPrefix+Hash(refreshToken), &familyID)
+	if err == nil {
+		return familyID, nil
+	}
+
+	if !legacyKey(refreshToken) {
+		return "", err
+	}
+
+	if legacyErr := conn.Get(rotatedRefreshTokenKeyPrefix+refreshToken, &familyID); legacyErr != nil {
+		return "", err
+	}
+
+	return familyID, nil
+}
+
+// findTokenFamily find token family by id
+func (s *service) findTokenFamily(familyID string) (*tokenFamily, error) {
+	family := &tokenFamily{}
+	err := redis.GetConnection().Get(tokenFamilyKeyPrefix+familyID, family)
+	if err != nil {
+		return nil, err
+	}
+
+	return family, nil
+}
+
+// storeTokens store hashes of access token and refresh token in family
+func (s *service) storeTokens(family *tokenFamily, a *models.RefreshToken) error {
+	conn := redis.GetConnection()
+	accessTokenHash := Hash(a.JWTToken)
+	accessTokenExpireTime := s.config.JWT.ExpireTime
+	if family.ActorID != 0 {
+		accessTokenExpireTime = s.config.JWT.ImpersonateExpireTime
+	}
+
+	err := conn.Set(accessTokenKey(accessTokenHash), family.UserID, accessTokenExpireTime)
+	if err != nil {
+		logrus.Errorf("set jwt token error: %s", err)
+		return err
+	}
+
+	if a.RefreshToken != "" {
+		refreshTokenHash := Hash(a.RefreshToken)
+		record := &refreshTokenRecord{UserID: family.UserID, FamilyID: family.ID}
+		err = conn.Set(refreshTokenKey(refreshTokenHash), record, s.config.JWT.RefreshTokenExpireTime)
+		if err != nil {
+			logrus.Errorf("set refresh token error: %s", err)
+			return err
+		}
+
+		family.RefreshToken = ""
+		family.RefreshTokenHash = refreshTokenHash
+	}
+
+	family.AccessTokenHashes = append(liveAccessTokenHashes(family.AccessTokenHashes), accessTokenHash)
+	err = conn.Set(tokenFamilyKeyPrefix+family.ID, family, family.expireTime())
+	if err != nil {
+		logrus.Errorf("set token family error: %s", err)
+		return err
+	}
+
+	err = conn.SetAdd(userSessionsKey(family.UserID), family.ID, s.config.JWT.RefreshTokenExpireTime)
+	if err != nil {
+		logrus.Errorf("add user session error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// liveAccessTokenHashes drop hashes of expired access tokens,
+// so families renewed for a long time do not grow without end
+func liveAccessTokenHashes(hashes []string) []string {
+	conn := redis.GetConnection()
+	live := []string{}
+	for _, hash := range hashes {
+		if ok, err := conn.Exists(accessTokenKey(hash)); err != nil || ok {
+			live = append(live, hash)
+		}
+	}
+
+	return live
+}
+
+// rotateRefreshToken claim refresh token by remembering it was rotated and remove it,
+// the claim is atomic so of concurrent renews with the same token only one gets true
+func (s *service) rotateRefreshToken(family *tokenFamily, refreshToken string) (bool, error) {
+	conn := redis.GetConnection()
+	hash := Hash(refreshToken)
+	claimed, err := conn.SetNX(rotatedRefreshTokenKeyPrefix+hash, family.ID, s.config.JWT.RefreshTokenExpireTime)
+	if err != nil {
+		logrus.Errorf("set rotated refresh token error: %s", err)
+		return false, err
+	}
+
+	if !claimed {
+		return false, nil
+	}
+
+	err = conn.Delete(refreshTokenKey(hash))
+	if err != nil {
+		logrus.Errorf("delete refresh token in redis error: %s", err)
+		return false, err
+	}
+
+	return true, nil
+}
+
+// revokeTokenFamily delete every live token in family
+func (s *service) revokeTokenFamily(family *tokenFamily) error {
+	conn := redis.GetConnection()
+	keys := []string{}
+	for _, hash := range family.AccessTokenHashes {
+		keys = append(keys, accessTokenKey(hash), verifiedTokenKey(hash))
+	}
+
+	for _, accessToken := range family.AccessTokens {
+		keys = append(keys, accessToken, accessTokenKey(Hash(accessToken)))
+	}
+
+	if hash := family.refreshTokenHash(); hash != "" {
+		keys = append(keys, refreshTokenKey(hash))
+	}
+
+	if family.RefreshToken != "" {
+		keys = append(keys, family.RefreshToken)
+	}
+
+	for _, key := range keys {
+		if err := conn.Delete(key); err != nil {
+			logrus.Errorf("delete token in redis error: %s", err)
+			return err
+		}
+	}
+
+	if err := conn.Delete(tokenFamilyKeyPrefix + family.ID); err != nil {
+		logrus.Errorf("delete token family in redis error: %s", err)
+		return err
+	}
+
+	if err := conn.SetRemove(userSessionsKey(family.UserID), family.ID); err != nil {
+		logrus.Errorf("remove user session in redis error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// userSessionsKey key of set of token family ids by user
+func userSessionsKey(userID uint) string {
+	return fmt.Sprintf("%s%d", userSessionsKeyPrefix, userID)
+}
+
+// findUserTokenFamilies find live token families of user,
+// expired families are removed from the user index
+func (s *service) findUserTokenFamilies(userID uint) ([]*tokenFamily, error) {
+	conn := redis.GetConnection()
+	ids, err := conn.SetMembers(userSessionsKey(userID))
+	if err != nil {
+		logrus.Errorf("get user sessions userID=%d error: %s", userID, err)
+		return nil, err
+	}
+
+	families := []*tokenFamily{}
+	for _, id := range ids {
+		family, err := s.findTokenFamily(id)
+		if err != nil {
+			_ = conn.SetRemove(userSessionsKey(userID), id)
+			continue
+		}
+
+		families = append(families, family)
+	}
+
+	return families, nil
+}
+
+// revokeAccessToken delete access token, returns false when it is not a valid access token
+func (s *service) revokeAccessToken(accessToken string) (bool, error) {
+	t, err := ParseAccessToken(accessToken)
+	if err != nil || !t.Valid {
+		return false, nil
+	}
+
+	conn := redis.GetConnection()
+	hash := Hash(accessToken)
+	for _, key := range []string{accessTokenKey(hash), verifiedTokenKey(hash)} {
+		if err := conn.Delete(key); err != nil {
+			logrus.Errorf("delete jwt token in redis error: %s", err)
+			return true, err
+		}
+	}
+
+	if legacyKey(accessToken) {
+		if err := conn.Delete(accessToken); err != nil {
+			logrus.Errorf("delete jwt token in redis error: %s", err)
+			return true, err
+		}
+	}
+
+	return true, nil
+}
+
+// revokeRefreshToken revoke refresh token with its token family,
+// returns false when it is not a live refresh token
+func (s *service) revokeRefreshToken(refreshToken string) (bool, error) {
+	record, err := s.findRefreshToken(refreshToken)
+	if err != nil {
+		return false, nil
+	}
+
+	if record.FamilyID != "" {
+		family, err := s.findTokenFamily(record.FamilyID)
+		if err == nil {
+			return true, s.revokeTokenFamily(family)
+		}
+	}
+
+	if err := redis.GetConnection().Delete(refreshTokenKey(Hash(refreshToken))); err != nil {
+		logrus.Errorf("delete refresh token in redis error: %s", err)
+		return true, err
+	}
+
+	return true, nil
+}
+
+// introspectAccessToken access token is active while signature is valid and it is not revoked
+func (s *service) introspectAccessToken(accessToken string) *models.Introspection {
+	t, err := ParseAccessToken(accessToken)
+	if err != nil || !t.Valid {
+		return &models.Introspection{}
+	}
+
+	if _, err := FindAccessToken(accessToken); err != nil {
+		return &models.Introspection{}
+	}
+
+	claims := t.Claims.(*context.Claims)
+	return &models.Introspection{
+		Active:    true,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		TokenType: models.TokenTypeHintAccessToken,
+		Exp:       claims.ExpiresAt,
+		Iat:       claims.IssuedAt,
+		Sub:       claims.Subject,
+		Aud:       claims.Audience,
+		Iss:       claims.Issuer,
+		Jti:       claims.Id,
+		Role:      claims.Role,
+		SessionID: claims.SessionID,
+		Act:       claims.Actor,
+	}
+}
+
+// introspectRefreshToken refresh token is active while it is stored and its family is not revoked
+func (s *service) introspectRefreshToken(refreshToken string) *models.Introspection {
+	record, err := s.findRefreshToken(refreshToken)
+	if err != nil {
+		return &models.Introspection{}
+	}
+
+	i := &models.Introspection{
+		Active:    true,
+		TokenType: models.TokenTypeHintRefreshToken,
+		Sub:       fmt.Sprintf("%d", record.UserID),
+	}
+
+	if record.FamilyID != "" {
+		family, err := s.findTokenFamily(record.FamilyID)
+		if err != nil {
+			return &models.Introspection{}
+		}
+
+		i.SessionID = family.ID
+		i.Iat = family.LastUsedAt.Unix()
+		i.Scope = family.Scope
+		i.ClientID = family.ClientID
+	}
+
+	return i
+}
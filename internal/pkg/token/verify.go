@@ -0,0 +1,95 @@
+package token
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/models"
+	"strconv"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// minimumVerifiedTokenCacheTime identities of tokens expiring sooner are not cached,
+	// redis keys set for a second or less never expire
+	minimumVerifiedTokenCacheTime = 2 * time.Second
+)
+
+// VerifyAccessToken verify access token of user on every authorized request,
+// the token must be signed by us, be for the application of source and not be revoked
+func VerifyAccessToken(accessToken, source string) (*jwt.Token, error) {
+	t, err := ParseAccessToken(accessToken)
+	if err != nil || !t.Valid {
+		return nil, config.RR.InvalidToken
+	}
+
+	// service tokens act for a client, not for a user
+	claims := t.Claims.(*context.Claims)
+	if claims.IsServiceToken() || !VerifyAudience(claims, source) {
+		return nil, config.RR.InvalidToken
+	}
+
+	userID, err := FindAccessToken(accessToken)
+	if err != nil {
+		return nil, config.RR.InvalidToken
+	}
+
+	if strconv.FormatUint(uint64(userID), 10) != claims.Subject {
+		return nil, config.RR.InvalidPermissionRole
+	}
+
+	return t, nil
+}
+
+// Verify verify access token for forward auth, identities of verified tokens are cached
+// for a short time and removed when the token is revoked
+func (s *service) Verify(c *context.Context, accessToken string) (*models.Identity, error) {
+	conn := redis.GetConnection()
+	key := verifiedTokenKey(Hash(accessToken))
+	identity := &models.Identity{}
+	if err := conn.Get(key, identity); err == nil {
+		if identity.ExpiresAt > time.Now().Unix() && (identity.Audience == "" || identity.ClientID != "" || identity.Audience == c.GetSource()) {
+			return identity, nil
+		}
+
+		return nil, s.result.InvalidToken
+	}
+
+	t, err := VerifyAccessToken(accessToken, c.GetSource())
+	if err != nil {
+		return nil, err
+	}
+
+	claims := t.Claims.(*context.Claims)
+	userID, _ := strconv.ParseUint(claims.Subject, 10, 64)
+	identity = &models.Identity{
+		UserID:    uint(userID),
+		Role:      claims.Role,
+		SessionID: claims.SessionID,
+		Scope:     claims.Scope,
+		ClientID:  claims.ClientID,
+		Audience:  claims.Audience,
+		ExpiresAt: claims.ExpiresAt,
+	}
+
+	if claims.Actor != nil {
+		actorID, _ := strconv.ParseUint(claims.Actor.Subject, 10, 64)
+		identity.ActorID = uint(actorID)
+	}
+
+	cacheTime := s.config.ForwardAuth.CacheTime
+	if remaining := time.Until(time.Unix(claims.ExpiresAt, 0)); remaining < cacheTime {
+		cacheTime = remaining
+	}
+
+	if cacheTime >= minimumVerifiedTokenCacheTime {
+		if err := conn.Set(key, identity, cacheTime); err != nil {
+			logrus.Errorf("cache verified token error: %s", err)
+		}
+	}
+
+	return identity, nil
+}
@@ -0,0 +1,165 @@
+// Package twofactor is a two-factor authentication package
+package twofactor
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/handlers"
+	"ecommerce-authen/internal/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	Enroll(c *fiber.Ctx) error
+	Confirm(c *fiber.Ctx) error
+	Disable(c *fiber.Ctx) error
+	RegenerateRecoveryCodes(c *fiber.Ctx) error
+	Verify(c *fiber.Ctx) error
+	BeginPasskey(c *fiber.Ctx) error
+	VerifyPasskey(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config  *config.Configs
+	result  *config.ReturnResult
+	service Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:  config.CF,
+		result:  config.RR,
+		service: NewService(),
+	}
+}
+
+// Enroll enroll authenticator app
+// @Tags TwoFactor
+// @Summary Enroll
+// @Description Start enrollment of authenticator app, returns otpauth uri and png qr code
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Success 200 {object} models.TwoFactorEnrollment
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/2fa/enroll [post]
+func (ep *endpoint) Enroll(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.Enroll)
+}
+
+// Confirm confirm authenticator app
+// @Tags TwoFactor
+// @Summary Confirm
+// @Description Enable two-factor authentication by code of enrolled authenticator app, returns recovery codes once
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.TwoFactorCodeRequest true "request body"
+// @Success 200 {object} models.RecoveryCodes
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/2fa/confirm [post]
+func (ep *endpoint) Confirm(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.Confirm, &request.TwoFactorCodeRequest{})
+}
+
+// Disable disable two-factor authentication
+// @Tags TwoFactor
+// @Summary Disable
+// @Description Disable two-factor authentication by code of authenticator app or recovery code
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.TwoFactorCodeRequest true "request body"
+// @Success 200 {object} models.Message
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/2fa/disable [post]
+func (ep *endpoint) Disable(c *fiber.Ctx) error {
+	return handlers.ResponseSuccess(c, ep.service.Disable, &request.TwoFactorCodeRequest{})
+}
+
+// RegenerateRecoveryCodes regenerate recovery codes
+// @Tags TwoFactor
+// @Summary RegenerateRecoveryCodes
+// @Description Replace recovery codes by code of authenticator app, returns new recovery codes once
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.TwoFactorCodeRequest true "request body"
+// @Success 200 {object} models.RecoveryCodes
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /me/2fa/recovery-codes [post]
+func (ep *endpoint) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.RegenerateRecoveryCodes, &request.TwoFactorCodeRequest{})
+}
+
+// Verify second step of sign in
+// @Tags Guest
+// @Summary VerifyTwoFactor
+// @Description Second step of sign in by two_factor_token of login response and code of authenticator app or recovery code
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.TwoFactorLoginRequest true "request body"
+// @Success 200 {object} models.RefreshToken
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/2fa/verify [post]
+func (ep *endpoint) Verify(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.Verify, &request.TwoFactorLoginRequest{})
+}
+
+// BeginPasskey begin second step of sign in by passkey
+// @Tags Guest
+// @Summary BeginTwoFactorPasskey
+// @Description Returns options for navigator.credentials.get to use a passkey as second step of sign in by two_factor_token of login response
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.TwoFactorTokenRequest true "request body"
+// @Success 200 {object} models.PasskeyOptions
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/2fa/passkey/begin [post]
+func (ep *endpoint) BeginPasskey(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.BeginPasskey, &request.TwoFactorTokenRequest{})
+}
+
+// VerifyPasskey second step of sign in by passkey
+// @Tags Guest
+// @Summary VerifyTwoFactorPasskey
+// @Description Second step of sign in by two_factor_token of login response and credential returned by navigator.credentials.get
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request body request.PasskeyLoginRequest true "request body"
+// @Success 200 {object} models.RefreshToken
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Router /g/2fa/passkey/finish [post]
+func (ep *endpoint) VerifyPasskey(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.VerifyPasskey, &request.PasskeyLoginRequest{})
+}
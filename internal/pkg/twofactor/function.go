@@ -0,0 +1,204 @@
+package twofactor
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/token"
+	"encoding/hex"
+	"errors"
+	"io"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// recoveryCodeCharacters characters of recovery code without look-alike characters
+	recoveryCodeCharacters = "23456789abcdefghjkmnpqrstuvwxyz"
+	// recoveryCodeLength length of recovery code without separator
+	recoveryCodeLength = 10
+)
+
+// findUser find user
+func (s *service) findUser(c *context.Context, userID uint) (*models.User, error) {
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), userID, user); err != nil {
+		logrus.Errorf("find user by id=%d error: %s", userID, err)
+		return nil, err
+	}
+
+	return user, nil
+}
+
+// verifyCode verify code of authenticator app of user, recovery codes are accepted
+// when allowed and can only be used once
+func (s *service) verifyCode(c *context.Context, user *models.User, code string, allowRecoveryCode bool) (recovery bool, ok bool, err error) {
+	secret, err := openSecret(user.TwoFactorSecret)
+	if err != nil {
+		logrus.Errorf("open two factor secret of userID=%d error: %s", user.ID, err)
+		return false, false, err
+	}
+
+	code = strings.TrimSpace(code)
+	if s.otp.ValidateAuthenticatorCode(code, secret) {
+		return false, s.markCodeUsed(user.ID, code), nil
+	}
+
+	if !allowRecoveryCode {
+		return false, false, nil
+	}
+
+	used, err := s.recoveryCodeRepository.MarkUsed(c.GetDatabase(), user.ID, token.Hash(normalizeRecoveryCode(code)), time.Now())
+	if err != nil {
+		logrus.Errorf("use recovery code of userID=%d error: %s", user.ID, err)
+		return false, false, err
+	}
+
+	return true, used, nil
+}
+
+// checkCode verify code like verifyCode, wrong codes lock user out
+// for every challenge until VERIFICATION_CODE.LOCKOUT_TIME has passed
+func (s *service) checkCode(c *context.Context, user *models.User, code string, allowRecoveryCode bool) (bool, error) {
+	if s.lockedOut(user.ID) {
+		return false, s.result.ReachLimit
+	}
+
+	recovery, ok, err := s.verifyCode(c, user, code, allowRecoveryCode)
+	if err != nil {
+		return false, err
+	}
+
+	if !ok {
+		s.countFailure(user.ID)
+		return false, s.result.InvalidOTP
+	}
+
+	s.clearFailures(user.ID)
+	return recovery, nil
+}
+
+// replaceRecoveryCodes generate new recovery codes of user, previous codes can not be used anymore
+func (s *service) replaceRecoveryCodes(c *context.Context, user *models.User) (*models.RecoveryCodes, error) {
+	db := c.GetDatabase()
+	if err := s.recoveryCodeRepository.DeleteByUserID(db, user.ID); err != nil {
+		logrus.Errorf("delete recovery codes of userID=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	codes := &models.RecoveryCodes{Codes: []string{}}
+	entities := []models.RecoveryCode{}
+	for i := 0; i < s.config.TwoFactor.RecoveryCodeCount; i++ {
+		code, err := generateRecoveryCode()
+		if err != nil {
+			logrus.Errorf("generate recovery code error: %s", err)
+			return nil, err
+		}
+
+		codes.Codes = append(codes.Codes, formatRecoveryCode(code))
+		entities = append(entities, models.RecoveryCode{UserID: user.ID, CodeHash: token.Hash(code)})
+	}
+
+	if len(entities) == 0 {
+		return codes, nil
+	}
+
+	if err := s.recoveryCodeRepository.CreateInBatch(db, &entities, len(entities)); err != nil {
+		logrus.Errorf("create recovery codes of userID=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+// generateRecoveryCode random recovery code
+func generateRecoveryCode() (string, error) {
+	b := make([]byte, recoveryCodeLength)
+	for i := range b {
+		n, err := rand.Int(rand.Reader, big.NewInt(int64(len(recoveryCodeCharacters))))
+		if err != nil {
+			return "", err
+		}
+
+		b[i] = recoveryCodeCharacters[n.Int64()]
+	}
+
+	return string(b), nil
+}
+
+// normalizeRecoveryCode recovery code typed by user, case and separators are ignored
+func normalizeRecoveryCode(code string) string {
+	return strings.Map(func(r rune) rune {
+		if r >= 'A' && r <= 'Z' {
+			r += 'a' - 'A'
+		}
+
+		if !strings.ContainsRune(recoveryCodeCharacters, r) {
+			return -1
+		}
+
+		return r
+	}, code)
+}
+
+// formatRecoveryCode recovery code shown to user, e.g. 7hk2m-x9qpa
+func formatRecoveryCode(code string) string {
+	return code[:recoveryCodeLength/2] + "-" + code[recoveryCodeLength/2:]
+}
+
+// secretCipher aes-gcm keyed by application secret
+func secretCipher() (cipher.AEAD, error) {
+	key := sha256.Sum256([]byte(config.CF.App.SecretKey))
+	block, err := aes.NewCipher(key[:])
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+// sealSecret encrypt authenticator secret to keep in database
+func sealSecret(secret string) (string, error) {
+	aead, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := io.ReadFull(rand.Reader, nonce); err != nil {
+		return "", err
+	}
+
+	return hex.EncodeToString(aead.Seal(nonce, nonce, []byte(secret), nil)), nil
+}
+
+// openSecret decrypt authenticator secret kept in database
+func openSecret(sealed string) (string, error) {
+	aead, err := secretCipher()
+	if err != nil {
+		return "", err
+	}
+
+	b, err := hex.DecodeString(sealed)
+	if err != nil {
+		return "", err
+	}
+
+	if len(b) < aead.NonceSize() {
+		return "", errors.New("sealed secret too short")
+	}
+
+	secret, err := aead.Open(nil, b[:aead.NonceSize()], b[aead.NonceSize():], nil)
+	if err != nil {
+		return "", err
+	}
+
+	return string(secret), nil
+}
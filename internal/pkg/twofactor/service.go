@@ -0,0 +1,321 @@
+package twofactor
+
+import (
+	"ecommerce-authen/internal/core/audit"
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/core/otp"
+	"ecommerce-authen/internal/core/utils"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/passkey"
+	"ecommerce-authen/internal/pkg/password"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/repositories"
+	"ecommerce-authen/internal/request"
+	"encoding/base64"
+	"fmt"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/sirupsen/logrus"
+)
+
+// Service service interface
+type Service interface {
+	Enroll(c *context.Context) (*models.TwoFactorEnrollment, error)
+	Confirm(c *context.Context, request *request.TwoFactorCodeRequest) (*models.RecoveryCodes, error)
+	Disable(c *context.Context, request *request.TwoFactorCodeRequest) error
+	RegenerateRecoveryCodes(c *context.Context, request *request.TwoFactorCodeRequest) (*models.RecoveryCodes, error)
+	Challenge(user *models.User, grant token.Grant) (*models.RefreshToken, error)
+	Verify(c *context.Context, request *request.TwoFactorLoginRequest) (*models.RefreshToken, error)
+	BeginPasskey(c *context.Context, request *request.TwoFactorTokenRequest) (*models.PasskeyOptions, error)
+	VerifyPasskey(c *context.Context, request *request.PasskeyLoginRequest) (*models.RefreshToken, error)
+}
+
+type service struct {
+	config                 *config.Configs
+	result                 *config.ReturnResult
+	userRepository         repositories.UserRepository
+	recoveryCodeRepository repositories.RecoveryCodeRepository
+	tokenService           token.Service
+	passwordService        password.Service
+	passkeyService         passkey.Service
+	otp                    otp.Interface
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config:                 config.CF,
+		result:                 config.RR,
+		userRepository:         repositories.UserNewRepository(),
+		recoveryCodeRepository: repositories.RecoveryCodeNewRepository(),
+		tokenService:           token.NewService(),
+		passwordService:        password.NewService(),
+		passkeyService:         passkey.NewService(),
+		otp:                    otp.New(),
+	}
+}
+
+// Enroll start enrollment of authenticator app of current user,
+// two-factor authentication is enabled once a code of the app is confirmed
+func (s *service) Enroll(c *context.Context) (*models.TwoFactorEnrollment, error) {
+	if c.GetClaims().IsImpersonation() {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	user, err := s.findUser(c, c.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil, s.result.TwoFactorAlreadyEnabled
+	}
+
+	account := user.Email
+	if account == "" {
+		account = user.PhoneNumber
+	}
+
+	if account == "" {
+		account = fmt.Sprintf("%d", user.ID)
+	}
+
+	key, err := s.otp.GenerateAuthenticatorKey(s.config.TwoFactor.Issuer, account)
+	if err != nil {
+		logrus.Errorf("generate authenticator key error: %s", err)
+		return nil, err
+	}
+
+	if err := s.storeEnrollment(user.ID, key.Secret); err != nil {
+		return nil, err
+	}
+
+	return &models.TwoFactorEnrollment{
+		Secret:     key.Secret,
+		OTPAuthURI: key.URI,
+		QRCode:     "data:image/png;base64," + base64.StdEncoding.EncodeToString(key.QRCode),
+		ExpiresAt:  time.Now().Add(s.config.TwoFactor.EnrollmentExpireTime),
+	}, nil
+}
+
+// Confirm enable two-factor authentication by code of enrolled authenticator app
+func (s *service) Confirm(c *context.Context, request *request.TwoFactorCodeRequest) (*models.RecoveryCodes, error) {
+	if c.GetClaims().IsImpersonation() {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	user, err := s.findUser(c, c.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+
+	if user.TwoFactorEnabled() {
+		return nil, s.result.TwoFactorAlreadyEnabled
+	}
+
+	secret, err := s.findEnrollment(user.ID)
+	if err != nil {
+		return nil, s.result.InvalidCodeOrExpired
+	}
+
+	if !s.otp.ValidateAuthenticatorCode(request.Code, secret) || !s.markCodeUsed(user.ID, request.Code) {
+		return nil, s.result.InvalidOTP
+	}
+
+	sealed, err := sealSecret(secret)
+	if err != nil {
+		logrus.Errorf("seal two factor secret error: %s", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	user.TwoFactorSecret = sealed
+	user.TwoFactorEnabledAt = &now
+	if err := s.userRepository.Update(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("enable two factor of userID=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	codes, err := s.replaceRecoveryCodes(c, user)
+	if err != nil {
+		return nil, err
+	}
+
+	s.deleteEnrollment(user.ID)
+	s.audit(c, audit.EventTwoFactorEnabled, user)
+	return codes, nil
+}
+
+// Disable disable two-factor authentication by code of authenticator app or recovery code
+func (s *service) Disable(c *context.Context, request *request.TwoFactorCodeRequest) error {
+	if c.GetClaims().IsImpersonation() {
+		return s.result.InvalidPermissionRole
+	}
+
+	user, err := s.findUser(c, c.GetUserID())
+	if err != nil {
+		return err
+	}
+
+	if !user.TwoFactorEnabled() {
+		return s.result.TwoFactorNotEnabled
+	}
+
+	if _, err := s.checkCode(c, user, request.Code, true); err != nil {
+		return err
+	}
+
+	user.TwoFactorSecret = ""
+	user.TwoFactorEnabledAt = nil
+	if err := s.userRepository.Update(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("disable two factor of userID=%d error: %s", user.ID, err)
+		return err
+	}
+
+	if err := s.recoveryCodeRepository.DeleteByUserID(c.GetDatabase(), user.ID); err != nil {
+		logrus.Errorf("delete recovery codes of userID=%d error: %s", user.ID, err)
+		return err
+	}
+
+	s.audit(c, audit.EventTwoFactorDisabled, user)
+	return nil
+}
+
+// RegenerateRecoveryCodes replace recovery codes by code of authenticator app
+func (s *service) RegenerateRecoveryCodes(c *context.Context, request *request.TwoFactorCodeRequest) (*models.RecoveryCodes, error) {
+	if c.GetClaims().IsImpersonation() {
+		return nil, s.result.InvalidPermissionRole
+	}
+
+	user, err := s.findUser(c, c.GetUserID())
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TwoFactorEnabled() {
+		return nil, s.result.TwoFactorNotEnabled
+	}
+
+	if _, err := s.checkCode(c, user, request.Code, false); err != nil {
+		return nil, err
+	}
+
+	return s.replaceRecoveryCodes(c, user)
+}
+
+// Challenge issue token for second step of sign in instead of tokens,
+// the grant of first step is kept until the second step is done
+func (s *service) Challenge(user *models.User, grant token.Grant) (*models.RefreshToken, error) {
+	challengeToken, err := utils.RandomToken(32)
+	if err != nil {
+		logrus.Errorf("generate two factor token error: %s", err)
+		return nil, err
+	}
+
+	ch := &challenge{UserID: user.ID, Grant: grant}
+	if err := s.storeChallenge(challengeToken, ch, s.config.TwoFactor.ChallengeExpireTime); err != nil {
+		return nil, err
+	}
+
+	return &models.RefreshToken{
+		UserID:         user.ID,
+		Role:           user.Role,
+		TwoFactorToken: challengeToken,
+	}, nil
+}
+
+// Verify second step of sign in by code of authenticator app or recovery code
+func (s *service) Verify(c *context.Context, request *request.TwoFactorLoginRequest) (*models.RefreshToken, error) {
+	ch, user, err := s.findChallengeUser(c, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	recovery, err := s.checkCode(c, user, request.Code, true)
+	if err != nil {
+		s.countChallengeAttempt(request.Token, ch)
+		return nil, err
+	}
+
+	s.deleteChallenge(request.Token)
+	if recovery {
+		s.audit(c, audit.EventRecoveryCodeUsed, user)
+	}
+
+	return s.complete(c, user, ch.Grant, "otp")
+}
+
+// BeginPasskey start ceremony of second step of sign in by passkey instead of code
+func (s *service) BeginPasskey(c *context.Context, request *request.TwoFactorTokenRequest) (*models.PasskeyOptions, error) {
+	_, user, err := s.findChallengeUser(c, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.passkeyService.BeginSecondFactor(c, user, request.Token)
+}
+
+// VerifyPasskey second step of sign in by passkey
+func (s *service) VerifyPasskey(c *context.Context, request *request.PasskeyLoginRequest) (*models.RefreshToken, error) {
+	ch, user, err := s.findChallengeUser(c, request.Token)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.passkeyService.FinishSecondFactor(c, user, request); err != nil {
+		s.countChallengeAttempt(request.Token, ch)
+		return nil, err
+	}
+
+	s.deleteChallenge(request.Token)
+	return s.complete(c, user, ch.Grant, "hwk")
+}
+
+// findChallengeUser find challenge of token and its user who can still finish sign in
+func (s *service) findChallengeUser(c *context.Context, challengeToken string) (*challenge, *models.User, error) {
+	ch, err := s.findChallenge(challengeToken)
+	if err != nil {
+		return nil, nil, s.result.InvalidCodeOrExpired
+	}
+
+	if ch.Attempts >= maxChallengeAttempts {
+		return nil, nil, s.result.ReachLimit
+	}
+
+	user, err := s.findUser(c, ch.UserID)
+	if err != nil {
+		return nil, nil, s.result.InvalidCodeOrExpired
+	}
+
+	if user.Deactivated() {
+		return nil, nil, s.result.BlockedUser
+	}
+
+	if !user.TwoFactorEnabled() {
+		return nil, nil, s.result.InvalidCodeOrExpired
+	}
+
+	return ch, user, nil
+}
+
+// complete issue tokens of sign in after second step by method,
+// a change password token is issued instead when password is expired
+func (s *service) complete(c *context.Context, user *models.User, grant token.Grant, method string) (*models.RefreshToken, error) {
+	grant.AuthMethods = append(grant.LoginType.AuthMethods(), method, "mfa")
+	if grant.LoginType == models.LoginTypeNormal && s.passwordService.Expired(user) {
+		return s.passwordService.IssueChangeToken(user)
+	}
+
+	return s.tokenService.Create(c, user, grant)
+}
+
+// audit emit security event of user
+func (s *service) audit(c *context.Context, event audit.Event, user *models.User) {
+	audit.Security(event, logrus.Fields{
+		"user_id":    user.ID,
+		"ip":         c.IP(),
+		"user_agent": c.Get(fiber.HeaderUserAgent),
+	})
+}
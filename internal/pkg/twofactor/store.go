@@ -0,0 +1,134 @@
+package twofactor
+
+import (
+	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/pkg/token"
+	"fmt"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	enrollmentKeyPrefix = "two_factor_enrollment:"
+	challengeKeyPrefix  = "two_factor_challenge:"
+	usedCodeKeyPrefix   = "two_factor_used_code:"
+	failureKeyPrefix    = "two_factor_failure:"
+	// maxChallengeAttempts wrong codes allowed before user has to sign in again
+	maxChallengeAttempts = 5
+	// usedCodeExpireTime codes are valid one period before and after, 30 seconds each
+	usedCodeExpireTime = 90 * time.Second
+)
+
+// challenge sign in waiting for second step
+type challenge struct {
+	UserID   uint
+	Grant    token.Grant
+	Attempts int
+}
+
+// storeEnrollment store secret of user until it is confirmed or expired
+func (s *service) storeEnrollment(userID uint, secret string) error {
+	err := redis.GetConnection().Set(fmt.Sprintf("%s%d", enrollmentKeyPrefix, userID), secret, s.config.TwoFactor.EnrollmentExpireTime)
+	if err != nil {
+		logrus.Errorf("set two factor enrollment error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findEnrollment find secret of user waiting for confirmation
+func (s *service) findEnrollment(userID uint) (string, error) {
+	var secret string
+	if err := redis.GetConnection().Get(fmt.Sprintf("%s%d", enrollmentKeyPrefix, userID), &secret); err != nil {
+		return "", err
+	}
+
+	return secret, nil
+}
+
+// deleteEnrollment delete secret of user waiting for confirmation
+func (s *service) deleteEnrollment(userID uint) {
+	if err := redis.GetConnection().Delete(fmt.Sprintf("%s%d", enrollmentKeyPrefix, userID)); err != nil {
+		logrus.Errorf("delete two factor enrollment error: %s", err)
+	}
+}
+
+// storeChallenge store challenge by hash of its token until it expires
+func (s *service) storeChallenge(challengeToken string, ch *challenge, expireTime time.Duration) error {
+	err := redis.GetConnection().Set(challengeKeyPrefix+token.Hash(challengeToken), ch, expireTime)
+	if err != nil {
+		logrus.Errorf("set two factor challenge error: %s", err)
+		return err
+	}
+
+	return nil
+}
+
+// findChallenge find challenge of token
+func (s *service) findChallenge(challengeToken string) (*challenge, error) {
+	ch := &challenge{}
+	if err := redis.GetConnection().Get(challengeKeyPrefix+token.Hash(challengeToken), ch); err != nil {
+		return nil, err
+	}
+
+	return ch, nil
+}
+
+// countChallengeAttempt count wrong code, challenge is kept until its original expiry
+func (s *service) countChallengeAttempt(challengeToken string, ch *challenge) {
+	ch.Attempts++
+	ttl, err := redis.GetConnection().GetTTL(challengeKeyPrefix + token.Hash(challengeToken))
+	if err != nil || ttl <= time.Second {
+		return
+	}
+
+	_ = s.storeChallenge(challengeToken, ch, ttl)
+}
+
+// deleteChallenge delete challenge of token
+func (s *service) deleteChallenge(challengeToken string) {
+	if err := redis.GetConnection().Delete(challengeKeyPrefix + token.Hash(challengeToken)); err != nil {
+		logrus.Errorf("delete two factor challenge error: %s", err)
+	}
+}
+
+// markCodeUsed mark code of user as used in one command (SET NX), returns false when
+// it was already used so a code seen by someone else can not be replayed
+func (s *service) markCodeUsed(userID uint, code string) bool {
+	key := fmt.Sprintf("%s%d:%s", usedCodeKeyPrefix, userID, code)
+	marked, err := redis.GetConnection().SetNX(key, true, usedCodeExpireTime)
+	if err != nil {
+		logrus.Errorf("set two factor used code error: %s", err)
+		return false
+	}
+
+	return marked
+}
+
+// lockedOut too many wrong codes of user, across challenges and sign ins
+func (s *service) lockedOut(userID uint) bool {
+	count, err := redis.GetConnection().GetCounter(fmt.Sprintf("%s%d", failureKeyPrefix, userID))
+	if err != nil {
+		logrus.Errorf("get two factor failure error: %s", err)
+		return false
+	}
+
+	return count >= s.config.VerificationCode.MaxFailures
+}
+
+// countFailure count wrong code of user until lockout time has passed
+func (s *service) countFailure(userID uint) {
+	key := fmt.Sprintf("%s%d", failureKeyPrefix, userID)
+	if _, err := redis.GetConnection().Increment(key, s.config.VerificationCode.LockoutTime); err != nil {
+		logrus.Errorf("increment two factor failure error: %s", err)
+	}
+}
+
+// clearFailures clear wrong codes of user after a code was accepted
+func (s *service) clearFailures(userID uint) {
+	if err := redis.GetConnection().Delete(fmt.Sprintf("%s%d", failureKeyPrefix, userID)); err != nil {
+		logrus.Errorf("delete two factor failure error: %s", err)
+	}
+}
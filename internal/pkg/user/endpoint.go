@@ -0,0 +1,144 @@
+package user
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/handlers"
+	"ecommerce-authen/internal/request"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	GetUsers(c *fiber.Ctx) error
+	GetUser(c *fiber.Ctx) error
+	ChangeRole(c *fiber.Ctx) error
+	Deactivate(c *fiber.Ctx) error
+	Reactivate(c *fiber.Ctx) error
+	ForcePasswordReset(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config  *config.Configs
+	result  *config.ReturnResult
+	service Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:  config.CF,
+		result:  config.RR,
+		service: NewService(),
+	}
+}
+
+// GetUsers get users
+// @Tags Admin
+// @Summary Get users
+// @Description Search users with pagination, sort by id, email, created_at or last_online_at (prefix `-` for descending)
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param request query request.UserQuery false "query"
+// @Success 200 {object} models.Page{items=[]models.User}
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users [get]
+func (ep *endpoint) GetUsers(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.GetUsers, &request.UserQuery{})
+}
+
+// GetUser get user
+// @Tags Admin
+// @Summary Get user
+// @Description Get user
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id} [get]
+func (ep *endpoint) GetUser(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.GetUser, &request.GetOne{})
+}
+
+// ChangeRole change role of user
+// @Tags Admin
+// @Summary Change role of user
+// @Description Change user role (1 customer, 5 seller, 10 admin), admins can not change their own role
+// @Accept json
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Param request body request.ChangeRoleRequest true "request body"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/role [put]
+func (ep *endpoint) ChangeRole(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.ChangeRole, &request.ChangeRoleRequest{})
+}
+
+// Deactivate deactivate user
+// @Tags Admin
+// @Summary Deactivate user
+// @Description Deactivate user and revoke every session, deactivated users can not sign in
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/deactivate [post]
+func (ep *endpoint) Deactivate(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.Deactivate, &request.GetOne{})
+}
+
+// Reactivate reactivate user
+// @Tags Admin
+// @Summary Reactivate user
+// @Description Reactivate deactivated user
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/reactivate [post]
+func (ep *endpoint) Reactivate(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.Reactivate, &request.GetOne{})
+}
+
+// ForcePasswordReset force user to reset password
+// @Tags Admin
+// @Summary Force password reset
+// @Description Revoke every session of user, user has to reset password before signing in with password again
+// @Produce json
+// @Param Accept-Language header string false "(en, th)" default(th)
+// @Param id path int true "user id"
+// @Success 200 {object} models.User
+// @Failure 400 {object} models.Message
+// @Failure 401 {object} models.Message
+// @Failure 404 {object} models.Message
+// @Failure 410 {object} models.Message
+// @Security ApiKeyAuth
+// @Router /admin/users/{id}/password-reset [post]
+func (ep *endpoint) ForcePasswordReset(c *fiber.Ctx) error {
+	return handlers.ResponseObject(c, ep.service.ForcePasswordReset, &request.GetOne{})
+}
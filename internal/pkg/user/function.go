@@ -0,0 +1,75 @@
+package user
+
+import (
+	"ecommerce-authen/internal/core/audit"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// findUser find user by id
+func (s *service) findUser(c *context.Context, userID uint) (*models.User, error) {
+	user := &models.User{}
+	if err := s.userRepository.FindOneObjectByIDUInt(c.GetDatabase(), userID, user); err != nil {
+		logrus.Errorf("find user id=%d error: %s", userID, err)
+		return nil, s.result.Internal.DatabaseNotFound
+	}
+
+	return user, nil
+}
+
+// checkManageable signed in user holds every permission of user
+func (s *service) checkManageable(c *context.Context, userID uint) error {
+	ok, err := s.rbacService.CanManageUser(c, userID)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return s.result.InvalidPermissionRole
+	}
+
+	return nil
+}
+
+// checkGrantable signed in user holds every one of permissions
+func (s *service) checkGrantable(c *context.Context, permissions []string) error {
+	ok, err := s.rbacService.CanGrant(c, permissions)
+	if err != nil {
+		return err
+	}
+
+	if !ok {
+		return s.result.InvalidPermissionRole
+	}
+
+	return nil
+}
+
+// audit emit security event of admin changing user
+func (s *service) audit(c *context.Context, event audit.Event, user *models.User, fields logrus.Fields) {
+	if fields == nil {
+		fields = logrus.Fields{}
+	}
+
+	fields["user_id"] = user.ID
+	fields["admin_id"] = c.GetUserID()
+	fields["ip"] = c.IP()
+	audit.Security(event, fields)
+}
+
+// parseTime parse RFC 3339 time, empty value is no time
+func parseTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		return nil, err
+	}
+
+	return &t, nil
+}
@@ -0,0 +1,209 @@
+// Package user is an admin user management package
+package user
+
+import (
+	"ecommerce-authen/internal/core/audit"
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/rbac"
+	"ecommerce-authen/internal/pkg/token"
+	"ecommerce-authen/internal/repositories"
+	"ecommerce-authen/internal/request"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Service service interface
+type Service interface {
+	GetUsers(c *context.Context, request *request.UserQuery) (*models.Page, error)
+	GetUser(c *context.Context, request *request.GetOne) (*models.User, error)
+	ChangeRole(c *context.Context, request *request.ChangeRoleRequest) (*models.User, error)
+	Deactivate(c *context.Context, request *request.GetOne) (*models.User, error)
+	Reactivate(c *context.Context, request *request.GetOne) (*models.User, error)
+	ForcePasswordReset(c *context.Context, request *request.GetOne) (*models.User, error)
+}
+
+type service struct {
+	config         *config.Configs
+	result         *config.ReturnResult
+	userRepository repositories.UserRepository
+	tokenService   token.Service
+	rbacService    rbac.Service
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config:         config.CF,
+		result:         config.RR,
+		userRepository: repositories.UserNewRepository(),
+		tokenService:   token.NewService(),
+		rbacService:    rbac.NewService(),
+	}
+}
+
+// GetUsers get page of users
+func (s *service) GetUsers(c *context.Context, request *request.UserQuery) (*models.Page, error) {
+	filter := &repositories.UserFilter{
+		Email:       request.Email,
+		PhoneNumber: request.PhoneNumber,
+		Role:        request.Role,
+		LoginType:   request.LoginType,
+		Active:      request.Active,
+		Sort:        request.Sort,
+	}
+
+	var err error
+	if filter.CreatedFrom, err = parseTime(request.CreatedFrom); err != nil {
+		return nil, s.result.Internal.BadRequest
+	}
+
+	if filter.CreatedTo, err = parseTime(request.CreatedTo); err != nil {
+		return nil, s.result.Internal.BadRequest
+	}
+
+	users, total, err := s.userRepository.FindAllByFilter(c.GetDatabase(), request, filter)
+	if err != nil {
+		logrus.Errorf("find users error: %s", err)
+		return nil, err
+	}
+
+	page, size := repositories.PageOf(request)
+	return &models.Page{Page: page, Size: size, Total: total, Items: users}, nil
+}
+
+// GetUser get user
+func (s *service) GetUser(c *context.Context, request *request.GetOne) (*models.User, error) {
+	return s.findUser(c, request.ID)
+}
+
+// ChangeRole change user role and sign out every session, tokens carry the old role,
+// admins only change users without more permissions than them to roles they hold themselves
+func (s *service) ChangeRole(c *context.Context, request *request.ChangeRoleRequest) (*models.User, error) {
+	if request.Role.Name() == "" || request.ID == c.GetUserID() {
+		return nil, s.result.Internal.BadRequest
+	}
+
+	user, err := s.findUser(c, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkManageable(c, user.ID); err != nil {
+		return nil, err
+	}
+
+	permissions, err := s.rbacService.RolePermissions(c, request.Role.Name())
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkGrantable(c, permissions); err != nil {
+		return nil, err
+	}
+
+	previous := user.Role
+	user.Role = request.Role
+	if err := s.userRepository.Update(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("update role of user id=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	if err := s.rbacService.ClearUserRoles(user.ID); err != nil {
+		logrus.Errorf("clear cached roles of user id=%d error: %s", user.ID, err)
+	}
+
+	if err := s.tokenService.RevokeSessions(c, user.ID, ""); err != nil {
+		return nil, err
+	}
+
+	s.audit(c, audit.EventUserRoleChanged, user, logrus.Fields{"previous_role": previous, "role": user.Role})
+	return user, nil
+}
+
+// Deactivate deactivate user and sign out every session
+func (s *service) Deactivate(c *context.Context, request *request.GetOne) (*models.User, error) {
+	if request.ID == c.GetUserID() {
+		return nil, s.result.Internal.BadRequest
+	}
+
+	user, err := s.findUser(c, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkManageable(c, user.ID); err != nil {
+		return nil, err
+	}
+
+	if user.Deactivated() {
+		return user, nil
+	}
+
+	now := time.Now()
+	user.DeactivatedAt = &now
+	if err := s.userRepository.Update(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("deactivate user id=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	if err := s.tokenService.RevokeSessions(c, user.ID, ""); err != nil {
+		return nil, err
+	}
+
+	s.audit(c, audit.EventUserDeactivated, user, nil)
+	return user, nil
+}
+
+// Reactivate reactivate user
+func (s *service) Reactivate(c *context.Context, request *request.GetOne) (*models.User, error) {
+	user, err := s.findUser(c, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkManageable(c, user.ID); err != nil {
+		return nil, err
+	}
+
+	if !user.Deactivated() {
+		return user, nil
+	}
+
+	user.DeactivatedAt = nil
+	if err := s.userRepository.Update(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("reactivate user id=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	s.audit(c, audit.EventUserReactivated, user, nil)
+	return user, nil
+}
+
+// ForcePasswordReset sign out every session of user,
+// user has to reset password before signing in with password again
+func (s *service) ForcePasswordReset(c *context.Context, request *request.GetOne) (*models.User, error) {
+	user, err := s.findUser(c, request.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.checkManageable(c, user.ID); err != nil {
+		return nil, err
+	}
+
+	user.PasswordResetRequired = true
+	if err := s.userRepository.Update(c.GetDatabase(), user); err != nil {
+		logrus.Errorf("force password reset of user id=%d error: %s", user.ID, err)
+		return nil, err
+	}
+
+	if err := s.tokenService.RevokeSessions(c, user.ID, ""); err != nil {
+		return nil, err
+	}
+
+	s.audit(c, audit.EventPasswordResetForced, user, nil)
+	return user, nil
+}
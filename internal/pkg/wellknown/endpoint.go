@@ -0,0 +1,56 @@
+// Package wellknown is a well-known uris (RFC 8615) package
+package wellknown
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/handlers"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// Endpoint endpoint interface
+type Endpoint interface {
+	JWKS(c *fiber.Ctx) error
+	OpenIDConfiguration(c *fiber.Ctx) error
+}
+
+type endpoint struct {
+	config  *config.Configs
+	result  *config.ReturnResult
+	service Service
+}
+
+// NewEndpoint new endpoint
+func NewEndpoint() Endpoint {
+	return &endpoint{
+		config:  config.CF,
+		result:  config.RR,
+		service: NewService(),
+	}
+}
+
+// JWKS json web key set
+// @Tags WellKnown
+// @Summary JWKS
+// @Description Public keys for verify access token
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.JWKSet
+// @Failure 400 {object} models.Message
+// @Router /.well-known/jwks.json [get]
+func (ep *endpoint) JWKS(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.JWKS)
+}
+
+// OpenIDConfiguration openid provider metadata
+// @Tags WellKnown
+// @Summary OpenID configuration
+// @Description OpenID Connect discovery document
+// @Accept json
+// @Produce json
+// @Success 200 {object} models.OpenIDConfiguration
+// @Failure 400 {object} models.Message
+// @Router /.well-known/openid-configuration [get]
+func (ep *endpoint) OpenIDConfiguration(c *fiber.Ctx) error {
+	return handlers.ResponseObjectWithoutRequest(c, ep.service.OpenIDConfiguration)
+}
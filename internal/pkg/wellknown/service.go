@@ -0,0 +1,75 @@
+package wellknown
+
+import (
+	"ecommerce-authen/internal/core/config"
+	"ecommerce-authen/internal/core/context"
+	"ecommerce-authen/internal/models"
+	"ecommerce-authen/internal/pkg/token"
+)
+
+const (
+	// apiPrefix prefix of versioned api routes
+	apiPrefix = "/api/v1"
+)
+
+// Service service interface
+type Service interface {
+	JWKS(c *context.Context) (*models.JWKSet, error)
+	OpenIDConfiguration(c *context.Context) (*models.OpenIDConfiguration, error)
+}
+
+type service struct {
+	config *config.Configs
+	result *config.ReturnResult
+}
+
+// NewService new service
+func NewService() Service {
+	return &service{
+		config: config.CF,
+		result: config.RR,
+	}
+}
+
+// JWKS public keys for verify access token
+func (s *service) JWKS(c *context.Context) (*models.JWKSet, error) {
+	return token.PublicKeys(), nil
+}
+
+// OpenIDConfiguration openid provider metadata, endpoints are under the issuer url
+func (s *service) OpenIDConfiguration(c *context.Context) (*models.OpenIDConfiguration, error) {
+	issuer := s.config.OAuth.Issuer
+	api := issuer + apiPrefix
+	return &models.OpenIDConfiguration{
+		Issuer:                      issuer,
+		AuthorizationEndpoint:       api + "/oauth/authorize",
+		TokenEndpoint:               api + "/oauth/token",
+		UserInfoEndpoint:            api + "/oauth/userinfo",
+		JWKSURI:                     issuer + "/.well-known/jwks.json",
+		RevocationEndpoint:          api + "/oauth/revoke",
+		IntrospectionEndpoint:       api + "/oauth/introspect",
+		DeviceAuthorizationEndpoint: api + "/oauth/device_authorization",
+		ScopesSupported: []string{
+			models.ScopeOpenID,
+			models.ScopeProfile,
+			models.ScopeEmail,
+			models.ScopePhone,
+		},
+		ResponseTypesSupported: []string{models.ResponseTypeCode},
+		GrantTypesSupported: []string{
+			models.GrantTypeAuthorizationCode,
+			models.GrantTypeRefreshToken,
+			models.GrantTypeClientCredentials,
+			models.GrantTypeDeviceCode,
+			models.GrantTypeTokenExchange,
+		},
+		SubjectTypesSupported:             []string{"public"},
+		IDTokenSigningAlgValuesSupported:  []string{token.SigningAlgorithm()},
+		TokenEndpointAuthMethodsSupported: []string{"client_secret_basic", "client_secret_post", "none"},
+		CodeChallengeMethodsSupported:     []string{models.CodeChallengeMethodS256},
+		ClaimsSupported: []string{
+			"iss", "sub", "aud", "exp", "iat", "auth_time", "nonce",
+			"email", "email_verified", "phone_number", "name", "picture",
+		},
+	}, nil
+}
@@ -0,0 +1,38 @@
+package repositories
+
+import (
+	"ecommerce-authen/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// ClientRepository repo interface
+type ClientRepository interface {
+	Create(db *gorm.DB, i interface{}) error
+	Update(db *gorm.DB, i interface{}) error
+	FindOneObjectByIDUInt(db *gorm.DB, id uint, i interface{}) error
+	FindAll(db *gorm.DB, i interface{}) error
+	FindByClientID(db *gorm.DB, clientID string) (*models.Client, error)
+}
+
+type clientRepository struct {
+	Repository
+}
+
+// ClientNewRepository new sql repository
+func ClientNewRepository() ClientRepository {
+	return &clientRepository{
+		NewRepository(),
+	}
+}
+
+// FindByClientID find active client by client id
+func (repo *clientRepository) FindByClientID(db *gorm.DB, clientID string) (*models.Client, error) {
+	entity := &models.Client{}
+	err := db.Where("client_id = ? AND is_active = ?", clientID, true).First(entity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
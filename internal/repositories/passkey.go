@@ -0,0 +1,61 @@
+package repositories
+
+import (
+	"ecommerce-authen/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PasskeyRepository repo interface
+type PasskeyRepository interface {
+	Create(db *gorm.DB, i interface{}) error
+	Update(db *gorm.DB, i interface{}) error
+	HardDelete(db *gorm.DB, i interface{}) error
+	FindAllByUserID(db *gorm.DB, userID uint) ([]models.Passkey, error)
+	FindByCredentialID(db *gorm.DB, credentialID []byte) (*models.Passkey, error)
+	FindUserPasskey(db *gorm.DB, userID, id uint) (*models.Passkey, error)
+}
+
+type passkeyRepository struct {
+	Repository
+}
+
+// PasskeyNewRepository new sql repository
+func PasskeyNewRepository() PasskeyRepository {
+	return &passkeyRepository{
+		NewRepository(),
+	}
+}
+
+// FindAllByUserID find passkeys of user
+func (repo *passkeyRepository) FindAllByUserID(db *gorm.DB, userID uint) ([]models.Passkey, error) {
+	entities := []models.Passkey{}
+	err := db.Where("user_id = ?", userID).Order("id").Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// FindByCredentialID find passkey by webauthn credential id
+func (repo *passkeyRepository) FindByCredentialID(db *gorm.DB, credentialID []byte) (*models.Passkey, error) {
+	entity := &models.Passkey{}
+	err := db.Where("credential_id = ?", credentialID).First(entity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// FindUserPasskey find passkey of user
+func (repo *passkeyRepository) FindUserPasskey(db *gorm.DB, userID, id uint) (*models.Passkey, error) {
+	entity := &models.Passkey{}
+	err := db.Where("id = ? AND user_id = ?", id, userID).First(entity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
@@ -0,0 +1,47 @@
+package repositories
+
+import (
+	"ecommerce-authen/internal/models"
+
+	"gorm.io/gorm"
+)
+
+// PasswordHistoryRepository repo interface
+type PasswordHistoryRepository interface {
+	Create(db *gorm.DB, i interface{}) error
+	FindLatest(db *gorm.DB, userID uint, limit int) ([]models.PasswordHistory, error)
+	DeleteOlder(db *gorm.DB, userID uint, keep int) error
+}
+
+type passwordHistoryRepository struct {
+	Repository
+}
+
+// PasswordHistoryNewRepository new sql repository
+func PasswordHistoryNewRepository() PasswordHistoryRepository {
+	return &passwordHistoryRepository{
+		NewRepository(),
+	}
+}
+
+// FindLatest find latest passwords of user
+func (repo *passwordHistoryRepository) FindLatest(db *gorm.DB, userID uint, limit int) ([]models.PasswordHistory, error) {
+	entities := []models.PasswordHistory{}
+	err := db.Where("user_id = ?", userID).Order("id DESC").Limit(limit).Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// DeleteOlder delete passwords of user except the latest
+func (repo *passwordHistoryRepository) DeleteOlder(db *gorm.DB, userID uint, keep int) error {
+	latest := db.Model(&models.PasswordHistory{}).
+		Select("id").
+		Where("user_id = ?", userID).
+		Order("id DESC").
+		Limit(keep)
+
+	return db.Where("user_id = ? AND id NOT IN (?)", userID, latest).Delete(&models.PasswordHistory{}).Error
+}
@@ -0,0 +1,44 @@
+package repositories
+
+import (
+	"ecommerce-authen/internal/models"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// RecoveryCodeRepository repo interface
+type RecoveryCodeRepository interface {
+	CreateInBatch(db *gorm.DB, i interface{}, batchSize int) error
+	MarkUsed(db *gorm.DB, userID uint, codeHash string, usedAt time.Time) (bool, error)
+	DeleteByUserID(db *gorm.DB, userID uint) error
+}
+
+type recoveryCodeRepository struct {
+	Repository
+}
+
+// RecoveryCodeNewRepository new sql repository
+func RecoveryCodeNewRepository() RecoveryCodeRepository {
+	return &recoveryCodeRepository{
+		NewRepository(),
+	}
+}
+
+// MarkUsed mark unused recovery code of user by hash as used, false when there is none,
+// the condition on used_at makes a code usable only once even by concurrent requests
+func (repo *recoveryCodeRepository) MarkUsed(db *gorm.DB, userID uint, codeHash string, usedAt time.Time) (bool, error) {
+	result := db.Model(&models.RecoveryCode{}).
+		Where("user_id = ? AND code_hash = ? AND used_at IS NULL", userID, codeHash).
+		Update("used_at", usedAt)
+	if result.Error != nil {
+		return false, result.Error
+	}
+
+	return result.RowsAffected == 1, nil
+}
+
+// DeleteByUserID delete every recovery code of user
+func (repo *recoveryCodeRepository) DeleteByUserID(db *gorm.DB, userID uint) error {
+	return db.Where("user_id = ?", userID).Delete(&models.RecoveryCode{}).Error
+}
@@ -3,6 +3,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -153,4 +154,29 @@ const (
 	DefaultPage int = 1
 	// DefaultSize default size in page query
 	DefaultSize int = 20
+	// MaximumSize maximum size in page query
+	MaximumSize int = 100
 )
+
+// PageOf page and size of page form, out of range values are replaced by defaults
+func PageOf(form PageForm) (int, int) {
+	page, size := form.GetPage(), form.GetSize()
+	if page < 1 {
+		page = DefaultPage
+	}
+
+	if size < 1 {
+		size = DefaultSize
+	}
+
+	if size > MaximumSize {
+		size = MaximumSize
+	}
+
+	return page, size
+}
+
+// containsPattern like pattern of value contained anywhere, wildcards in value are escaped
+func containsPattern(value string) string {
+	return "%" + strings.NewReplacer(`\`, `\\`, "%", `\%`, "_", `\_`).Replace(value) + "%"
+}
@@ -0,0 +1,103 @@
+package repositories
+
+import (
+	"ecommerce-authen/internal/models"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// RoleRepository repo interface
+type RoleRepository interface {
+	Create(db *gorm.DB, i interface{}) error
+	Update(db *gorm.DB, i interface{}) error
+	HardDelete(db *gorm.DB, i interface{}) error
+	FindOneObjectByIDUInt(db *gorm.DB, id uint, i interface{}) error
+	FindAll(db *gorm.DB, i interface{}) error
+	FindAllByUintIDs(db *gorm.DB, ids []uint, i interface{}) error
+	FindByName(db *gorm.DB, name string) (*models.Role, error)
+	FindUserRoles(db *gorm.DB, userID uint, role models.UserRole) ([]models.Role, error)
+	ReplaceUserRoles(db *gorm.DB, userID uint, roleIDs []uint) error
+	DeleteRoleAssignments(db *gorm.DB, roleID uint) error
+	FindRoleUserIDs(db *gorm.DB, roleID uint) ([]uint, error)
+	Seed(db *gorm.DB, roles []models.Role) error
+}
+
+type roleRepository struct {
+	Repository
+}
+
+// RoleNewRepository new sql repository
+func RoleNewRepository() RoleRepository {
+	return &roleRepository{
+		NewRepository(),
+	}
+}
+
+// FindByName find role by name
+func (repo *roleRepository) FindByName(db *gorm.DB, name string) (*models.Role, error) {
+	entity := &models.Role{}
+	err := db.Where("name = ?", name).First(entity).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entity, nil
+}
+
+// FindUserRoles find roles of user, role of user role and assigned roles
+func (repo *roleRepository) FindUserRoles(db *gorm.DB, userID uint, role models.UserRole) ([]models.Role, error) {
+	entities := []models.Role{}
+	err := db.
+		Where("id IN (?)", db.Model(&models.UserRoleAssignment{}).Select("role_id").Where("user_id = ?", userID)).
+		Or("name = ?", role.Name()).
+		Order("id").
+		Find(&entities).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return entities, nil
+}
+
+// ReplaceUserRoles replace roles assigned to user
+func (repo *roleRepository) ReplaceUserRoles(db *gorm.DB, userID uint, roleIDs []uint) error {
+	if err := db.Where("user_id = ?", userID).Delete(&models.UserRoleAssignment{}).Error; err != nil {
+		return err
+	}
+
+	if len(roleIDs) == 0 {
+		return nil
+	}
+
+	assignments := make([]models.UserRoleAssignment, 0, len(roleIDs))
+	for _, id := range roleIDs {
+		assignments = append(assignments, models.UserRoleAssignment{UserID: userID, RoleID: id})
+	}
+
+	return db.Clauses(clause.OnConflict{DoNothing: true}).Create(&assignments).Error
+}
+
+// DeleteRoleAssignments unassign role from every user
+func (repo *roleRepository) DeleteRoleAssignments(db *gorm.DB, roleID uint) error {
+	return db.Where("role_id = ?", roleID).Delete(&models.UserRoleAssignment{}).Error
+}
+
+// FindRoleUserIDs find ids of users role is assigned to
+func (repo *roleRepository) FindRoleUserIDs(db *gorm.DB, roleID uint) ([]uint, error) {
+	ids := []uint{}
+	err := db.Model(&models.UserRoleAssignment{}).Where("role_id = ?", roleID).Pluck("user_id", &ids).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+// Seed create roles not created yet, existing roles are kept as they are
+func (repo *roleRepository) Seed(db *gorm.DB, roles []models.Role) error {
+	return db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "name"}},
+		DoNothing: true,
+	}).Create(&roles).Error
+}
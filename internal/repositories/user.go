@@ -3,6 +3,9 @@ package repositories
 import (
 	"ecommerce-authen/internal/models"
 
+	"strings"
+	"time"
+
 	"gorm.io/gorm"
 	"gorm.io/gorm/clause"
 )
@@ -19,8 +22,27 @@ type UserRepository interface {
 	FindOneByIDWithPreload(db *gorm.DB, userID uint) (*models.User, error)
 	FindByFacebookID(db *gorm.DB, tokenID string) (*models.User, error)
 	FindPhoneNumber(database *gorm.DB, phoneNumber string) (*models.User, error)
+	FindAllByFilter(db *gorm.DB, form PageForm, filter *UserFilter) ([]models.User, int64, error)
+}
+
+// UserFilter filter of user list
+type UserFilter struct {
+	Email       string
+	PhoneNumber string
+	Role        models.UserRole
+	LoginType   models.LoginType
+	Active      *bool
+	CreatedFrom *time.Time
+	CreatedTo   *time.Time
+	// Sort column to sort by, descending when prefixed by `-`
+	Sort string
 }
 
+var (
+	// userSortColumns columns users can be sorted by
+	userSortColumns = map[string]bool{"id": true, "email": true, "created_at": true, "last_online_at": true}
+)
+
 type userRepository struct {
 	Repository
 }
@@ -88,3 +110,74 @@ func (repo *userRepository) FindByFacebookID(db *gorm.DB, tokenID string) (*mode
 
 	return entity, nil
 }
+
+// FindAllByFilter find page of users by filter, query matches email or phone number
+func (repo *userRepository) FindAllByFilter(db *gorm.DB, form PageForm, filter *UserFilter) ([]models.User, int64, error) {
+	query := db.Model(&models.User{})
+	if q := form.GetQuery(); q != "" {
+		query = query.Where("email ILIKE ? OR phone_number LIKE ?", containsPattern(q), containsPattern(q))
+	}
+
+	if filter.Email != "" {
+		query = query.Where("email ILIKE ?", containsPattern(filter.Email))
+	}
+
+	if filter.PhoneNumber != "" {
+		query = query.Where("phone_number LIKE ?", containsPattern(filter.PhoneNumber))
+	}
+
+	if filter.Role != models.UnknownRole {
+		query = query.Where("role = ?", filter.Role)
+	}
+
+	switch filter.LoginType {
+	case models.LoginTypeNormal:
+		query = query.Where("password <> ''")
+	case models.LoginTypeGoogle:
+		query = query.Where("google_id <> ''")
+	case models.LoginTypeFacebook:
+		query = query.Where("facebook_id <> ''")
+	case models.LoginTypePhone:
+		query = query.Where("phone_verified_at IS NOT NULL")
+	}
+
+	if filter.Active != nil {
+		if *filter.Active {
+			query = query.Where("deactivated_at IS NULL")
+		} else {
+			query = query.Where("deactivated_at IS NOT NULL")
+		}
+	}
+
+	if filter.CreatedFrom != nil {
+		query = query.Where("created_at >= ?", filter.CreatedFrom)
+	}
+
+	if filter.CreatedTo != nil {
+		query = query.Where("created_at <= ?", filter.CreatedTo)
+	}
+
+	query = query.Session(&gorm.Session{})
+	var total int64
+	if err := query.Count(&total).Error; err != nil {
+		return nil, 0, err
+	}
+
+	order := "id"
+	column := strings.TrimPrefix(filter.Sort, "-")
+	if userSortColumns[column] {
+		order = column
+		if strings.HasPrefix(filter.Sort, "-") {
+			order += " DESC"
+		}
+	}
+
+	page, size := PageOf(form)
+	entities := []models.User{}
+	err := query.Order(order).Offset((page - 1) * size).Limit(size).Find(&entities).Error
+	if err != nil {
+		return nil, 0, err
+	}
+
+	return entities, total, nil
+}
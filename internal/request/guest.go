@@ -31,10 +31,46 @@ type EmailRequest struct {
 	Email string `json:"email" example:"test@hotmail.com"`
 }
 
-// ResetPassword request
+// VerifyEmailRequest verify email by token of verification link or by email and code
+type VerifyEmailRequest struct {
+	Email string `json:"email" example:"test@hotmail.com"`
+	Code  string `json:"code" example:"123456"`
+	Token string `json:"token"`
+}
+
+// ResetPassword request, reset by token of reset link or by email and otp
 type ResetPassword struct {
 	Otp             string `json:"otp"`
 	Email           string `json:"email" `
+	Token           string `json:"token"`
 	Password        string `json:"password" example:"P@ssw0rd"`
 	ConfirmPassword string `json:"confirm_password" example:"P@ssw0rd"`
 }
+
+// MagicLinkRequest request sign in link for email,
+// a link bound to the requesting device needs confirmation when opened on another device
+type MagicLinkRequest struct {
+	Email      string `json:"email" example:"test@hotmail.com"`
+	BindDevice bool   `json:"bind_device"`
+}
+
+// MagicLinkLoginRequest sign in by token of link sent to email,
+// code is the confirmation mailed when a bound link is opened on another device
+type MagicLinkLoginRequest struct {
+	Token       string `json:"token"`
+	DeviceToken string `json:"device_token"`
+	Code        string `json:"code"`
+}
+
+// PhoneCodeRequest request login code for phone number
+type PhoneCodeRequest struct {
+	PhoneNumber string `json:"phone_number" example:"0812345678"`
+}
+
+// PhoneLoginRequest sign in by code sent to phone number,
+// password is only needed for an account registered with the number before it was verified
+type PhoneLoginRequest struct {
+	PhoneNumber string `json:"phone_number" example:"0812345678"`
+	Code        string `json:"code" example:"123456"`
+	Password    string `json:"password,omitempty"`
+}
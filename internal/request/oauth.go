@@ -0,0 +1,67 @@
+package request
+
+// AuthorizeRequest authorization request (RFC 6749 section 4.1.1) with pkce (RFC 7636)
+type AuthorizeRequest struct {
+	ResponseType        string `json:"response_type" form:"response_type" query:"response_type" example:"code"`
+	ClientID            string `json:"client_id" form:"client_id" query:"client_id"`
+	RedirectURI         string `json:"redirect_uri" form:"redirect_uri" query:"redirect_uri"`
+	Scope               string `json:"scope" form:"scope" query:"scope"`
+	State               string `json:"state" form:"state" query:"state"`
+	CodeChallenge       string `json:"code_challenge" form:"code_challenge" query:"code_challenge"`
+	CodeChallengeMethod string `json:"code_challenge_method" form:"code_challenge_method" query:"code_challenge_method" example:"S256"`
+	Nonce               string `json:"nonce" form:"nonce" query:"nonce"`
+}
+
+// ApproveAuthorizeRequest authorization request approved or denied by logged in user
+type ApproveAuthorizeRequest struct {
+	AuthorizeRequest
+	Approve bool `json:"approve" form:"approve"`
+}
+
+// TokenRequest token request (RFC 6749 section 4.1.3 and 6),
+// client credentials may be sent with http basic authentication instead
+type TokenRequest struct {
+	GrantType    string `json:"grant_type" form:"grant_type" example:"authorization_code"`
+	Code         string `json:"code" form:"code"`
+	RedirectURI  string `json:"redirect_uri" form:"redirect_uri"`
+	CodeVerifier string `json:"code_verifier" form:"code_verifier"`
+	RefreshToken string `json:"refresh_token" form:"refresh_token"`
+	Scope        string `json:"scope" form:"scope"`
+	Audience     string `json:"audience" form:"audience"`
+	DeviceCode   string `json:"device_code" form:"device_code"`
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+
+	SubjectToken       string `json:"subject_token" form:"subject_token"`
+	SubjectTokenType   string `json:"subject_token_type" form:"subject_token_type" example:"urn:ietf:params:oauth:token-type:access_token"`
+	RequestedSubject   string `json:"requested_subject" form:"requested_subject"`
+	RequestedTokenType string `json:"requested_token_type" form:"requested_token_type"`
+}
+
+// DeviceAuthorizationRequest device authorization request (RFC 8628 section 3.1)
+type DeviceAuthorizationRequest struct {
+	ClientID     string `json:"client_id" form:"client_id"`
+	ClientSecret string `json:"client_secret" form:"client_secret"`
+	Scope        string `json:"scope" form:"scope"`
+}
+
+// DeviceVerificationRequest user code entered by logged in user
+type DeviceVerificationRequest struct {
+	UserCode string `json:"user_code" form:"user_code" query:"user_code" validate:"required"`
+	Approve  bool   `json:"approve" form:"approve"`
+}
+
+// ClientRequest oauth client request, new clients are active unless is_active is false
+type ClientRequest struct {
+	ID                 uint     `json:"-" path:"id" form:"id" query:"id"`
+	Name               string   `json:"name" validate:"required"`
+	RedirectURIs       []string `json:"redirect_uris"`
+	RedirectSchemes    []string `json:"redirect_schemes"`
+	GrantTypes         []string `json:"grant_types"`
+	Scopes             []string `json:"scopes"`
+	Audiences          []string `json:"audiences"`
+	Public             bool     `json:"public"`
+	FirstParty         bool     `json:"first_party"`
+	AllowIntrospection bool     `json:"allow_introspection"`
+	IsActive           *bool    `json:"is_active"`
+}
@@ -48,3 +48,23 @@ type GetQuery struct {
 	ShopID       uint       `json:"shop_id" form:"shop_id" query:"shop_id" `
 	CompanyID    uint       `json:"company_id" form:"company_id" query:"company_id" `
 }
+
+// UserSessionRequest user session request
+type UserSessionRequest struct {
+	UserID    uint   `json:"-" path:"id" form:"id" query:"id"`
+	SessionID string `json:"-" path:"session_id" form:"session_id" query:"session_id"`
+}
+
+// RoleRequest role request
+type RoleRequest struct {
+	ID          uint     `json:"-" path:"id" form:"id" query:"id"`
+	Name        string   `json:"name" validate:"required" example:"support"`
+	Description string   `json:"description" example:"Customer support"`
+	Permissions []string `json:"permissions" validate:"dive,required,excludesall= " example:"users:read"`
+}
+
+// UserRolesRequest user roles request
+type UserRolesRequest struct {
+	UserID  uint   `json:"-" path:"id" form:"id" query:"id"`
+	RoleIDs []uint `json:"role_ids"`
+}
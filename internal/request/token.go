@@ -4,3 +4,18 @@ package request
 type RefreshTokenRequest struct {
 	RefreshToken string `json:"refresh_token" validate:"required"`
 }
+
+// RevokeTokenRequest revoke token request (RFC 7009)
+type RevokeTokenRequest struct {
+	Token         string `json:"token" form:"token" validate:"required"`
+	TokenTypeHint string `json:"token_type_hint" form:"token_type_hint" example:"refresh_token"`
+}
+
+// IntrospectRequest token introspection request (RFC 7662),
+// client credentials may be sent with http basic authentication instead
+type IntrospectRequest struct {
+	Token         string `json:"token" form:"token" validate:"required"`
+	TokenTypeHint string `json:"token_type_hint" form:"token_type_hint" example:"access_token"`
+	ClientID      string `json:"client_id" form:"client_id"`
+	ClientSecret  string `json:"client_secret" form:"client_secret"`
+}
@@ -0,0 +1,91 @@
+package request
+
+import (
+	"ecommerce-authen/internal/models"
+	"encoding/json"
+)
+
+// UserQuery admin user query
+type UserQuery struct {
+	Page        int              `json:"page" query:"page" form:"page" example:"1"`
+	Size        int              `json:"size" query:"size" form:"size" example:"20"`
+	Query       string           `json:"query" query:"query" form:"query" example:"test"`
+	Email       string           `json:"email" query:"email" form:"email" example:"test@hotmail.com"`
+	PhoneNumber string           `json:"phone_number" query:"phone_number" form:"phone_number"`
+	Role        models.UserRole  `json:"role" query:"role" form:"role" example:"1"`
+	LoginType   models.LoginType `json:"login_type" query:"login_type" form:"login_type" example:"1"`
+	Active      *bool            `json:"active" query:"active" form:"active"`
+	CreatedFrom string           `json:"created_from" query:"created_from" form:"created_from" example:"2023-01-01T00:00:00+07:00"`
+	CreatedTo   string           `json:"created_to" query:"created_to" form:"created_to" example:"2023-12-31T23:59:59+07:00"`
+	Sort        string           `json:"sort" query:"sort" form:"sort" example:"-created_at"`
+}
+
+// GetPage get page
+func (q *UserQuery) GetPage() int {
+	return q.Page
+}
+
+// GetSize get size
+func (q *UserQuery) GetSize() int {
+	return q.Size
+}
+
+// GetQuery get query
+func (q *UserQuery) GetQuery() string {
+	return q.Query
+}
+
+// ChangeRoleRequest change role request
+type ChangeRoleRequest struct {
+	ID   uint            `json:"-" path:"id" form:"id" query:"id"`
+	Role models.UserRole `json:"role" validate:"required" example:"5"`
+}
+
+// ChangePasswordRequest change password request
+type ChangePasswordRequest struct {
+	CurrentPassword     string `json:"current_password" example:"P@ssw0rd"`
+	Password            string `json:"password" example:"N3wP@ssw0rd"`
+	ConfirmPassword     string `json:"confirm_password" example:"N3wP@ssw0rd"`
+	RevokeOtherSessions bool   `json:"revoke_other_sessions"`
+}
+
+// ChangeExpiredPasswordRequest change expired password by change password token
+type ChangeExpiredPasswordRequest struct {
+	Token           string `json:"token"`
+	Password        string `json:"password" example:"N3wP@ssw0rd"`
+	ConfirmPassword string `json:"confirm_password" example:"N3wP@ssw0rd"`
+}
+
+// TwoFactorCodeRequest code of authenticator app, or recovery code where accepted
+type TwoFactorCodeRequest struct {
+	Code string `json:"code" example:"123456"`
+}
+
+// TwoFactorLoginRequest second step of sign in by two factor token of login response
+type TwoFactorLoginRequest struct {
+	Token string `json:"token"`
+	Code  string `json:"code" example:"123456"`
+}
+
+// TwoFactorTokenRequest two factor token of login response
+type TwoFactorTokenRequest struct {
+	Token string `json:"token"`
+}
+
+// PasskeyRegistrationRequest credential created by navigator.credentials.create
+type PasskeyRegistrationRequest struct {
+	Nickname   string          `json:"nickname" example:"My phone"`
+	Credential json.RawMessage `json:"credential" swaggertype:"object"`
+}
+
+// PasskeyLoginRequest credential returned by navigator.credentials.get with token of its options
+type PasskeyLoginRequest struct {
+	Token      string          `json:"token"`
+	Credential json.RawMessage `json:"credential" swaggertype:"object"`
+}
+
+// PasskeyRequest rename passkey request
+type PasskeyRequest struct {
+	ID       uint   `json:"-" path:"id" form:"id" query:"id"`
+	Nickname string `json:"nickname" validate:"required" example:"My phone"`
+}
@@ -5,9 +5,13 @@ import (
 	"ecommerce-authen/docs"
 	"ecommerce-authen/internal/core/config"
 	"ecommerce-authen/internal/core/firebaseauth"
+	"ecommerce-authen/internal/core/mail"
 	"ecommerce-authen/internal/core/redis"
+	"ecommerce-authen/internal/core/sms"
 	"ecommerce-authen/internal/core/sql"
 	"ecommerce-authen/internal/handlers/routes"
+	"ecommerce-authen/internal/pkg/rbac"
+	"ecommerce-authen/internal/pkg/token"
 	"flag"
 	"fmt"
 
@@ -56,6 +60,13 @@ func main() {
 	}
 	//=======================================================
 
+	// Init jwt signing keys
+	err = token.InitKeyRing(config.CF)
+	if err != nil {
+		panic(err)
+	}
+	//=======================================================
+
 	// Init connection postgresql
 	err = sql.InitConnectionDatabase(config.CF.PostgreSQL)
 	if err != nil {
@@ -65,6 +76,12 @@ func main() {
 	if !config.CF.App.Release {
 		sql.Debug()
 	}
+
+	// Seed roles of user roles
+	err = rbac.SeedDefaultRoles(sql.Database)
+	if err != nil {
+		panic(err)
+	}
 	//======================================================
 
 	// Redis initial
@@ -79,6 +96,18 @@ func main() {
 	}
 	//=======================================================
 
+	// Init mail sender
+	if err := mail.Init(config.CF); err != nil {
+		panic(err)
+	}
+	//=======================================================
+
+	// Init sms provider
+	if err := sms.Init(config.CF); err != nil {
+		panic(err)
+	}
+	//=======================================================
+
 	// Init firebase auth client
 	if err := firebaseauth.NewClient(config.CF.Firebase.CredentialsFile); err != nil {
 		panic(err)
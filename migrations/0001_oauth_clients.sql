@@ -0,0 +1,25 @@
+-- oauth clients, client_id is looked up on every oauth request
+CREATE TABLE IF NOT EXISTS oauth_clients (
+    id                  bigserial PRIMARY KEY,
+    client_id           text        NOT NULL,
+    client_secret_hash  text        NOT NULL DEFAULT '',
+    name                text        NOT NULL,
+    redirect_uris       text[]      NOT NULL DEFAULT '{}',
+    grant_types         text[]      NOT NULL DEFAULT '{}',
+    scopes              text[]      NOT NULL DEFAULT '{}',
+    public              boolean     NOT NULL DEFAULT false,
+    first_party         boolean     NOT NULL DEFAULT false,
+    allow_introspection boolean     NOT NULL DEFAULT false,
+    is_active           boolean     NOT NULL DEFAULT true,
+    created_at          timestamptz,
+    updated_at          timestamptz,
+    deleted_at          timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_oauth_clients_client_id ON oauth_clients (client_id);
+
+-- audiences of service tokens issued to the client
+ALTER TABLE oauth_clients ADD COLUMN IF NOT EXISTS audiences text[] NOT NULL DEFAULT '{}';
+
+-- custom schemes of native apps allowed in redirect uris
+ALTER TABLE oauth_clients ADD COLUMN IF NOT EXISTS redirect_schemes text[] NOT NULL DEFAULT '{}';
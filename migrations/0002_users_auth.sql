@@ -0,0 +1,13 @@
+-- columns added to users by sign in features, each with the feature that reads it
+-- email verification
+ALTER TABLE users ADD COLUMN IF NOT EXISTS email_verified_at timestamptz;
+
+-- password expiry
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_changed_at timestamptz;
+
+-- phone login
+ALTER TABLE users ADD COLUMN IF NOT EXISTS phone_verified_at timestamptz;
+
+-- authenticator app 2FA
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_secret text NOT NULL DEFAULT '';
+ALTER TABLE users ADD COLUMN IF NOT EXISTS two_factor_enabled_at timestamptz;
@@ -0,0 +1,9 @@
+-- bcrypt hashes of previous passwords, the last PASSWORD_POLICY.HISTORY_SIZE can not be used again
+CREATE TABLE IF NOT EXISTS password_histories (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint      NOT NULL,
+    password   text        NOT NULL,
+    created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_password_histories_user_id ON password_histories (user_id);
@@ -0,0 +1,10 @@
+-- one-time recovery codes of authenticator app 2FA, kept only as HMAC
+CREATE TABLE IF NOT EXISTS recovery_codes (
+    id         bigserial PRIMARY KEY,
+    user_id    bigint      NOT NULL,
+    code_hash  text        NOT NULL,
+    used_at    timestamptz,
+    created_at timestamptz
+);
+
+CREATE INDEX IF NOT EXISTS idx_recovery_codes_user_id ON recovery_codes (user_id);
@@ -0,0 +1,20 @@
+-- webauthn credentials of users, credential_id is looked up on every passkey sign in
+CREATE TABLE IF NOT EXISTS passkeys (
+    id               bigserial PRIMARY KEY,
+    user_id          bigint      NOT NULL,
+    credential_id    bytea       NOT NULL,
+    public_key       bytea       NOT NULL,
+    attestation_type text        NOT NULL DEFAULT '',
+    aaguid           bytea,
+    sign_count       bigint      NOT NULL DEFAULT 0,
+    transports       text[]      NOT NULL DEFAULT '{}',
+    backup_eligible  boolean     NOT NULL DEFAULT false,
+    backup_state     boolean     NOT NULL DEFAULT false,
+    nickname         text        NOT NULL DEFAULT '',
+    last_used_at     timestamptz,
+    created_at       timestamptz,
+    updated_at       timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_passkeys_credential_id ON passkeys (credential_id);
+CREATE INDEX IF NOT EXISTS idx_passkeys_user_id ON passkeys (user_id);
@@ -0,0 +1,23 @@
+-- roles and extra roles of users (RBAC), run before first start:
+-- roles of user roles are seeded on start with ON CONFLICT (name) which needs the unique index
+CREATE TABLE IF NOT EXISTS roles (
+    id          bigserial PRIMARY KEY,
+    name        text        NOT NULL,
+    description text        NOT NULL DEFAULT '',
+    permissions text[]      NOT NULL DEFAULT '{}',
+    system      boolean     NOT NULL DEFAULT false,
+    created_at  timestamptz,
+    updated_at  timestamptz,
+    deleted_at  timestamptz
+);
+
+CREATE UNIQUE INDEX IF NOT EXISTS idx_roles_name ON roles (name);
+
+CREATE TABLE IF NOT EXISTS user_roles (
+    user_id    bigint NOT NULL,
+    role_id    bigint NOT NULL REFERENCES roles (id) ON DELETE CASCADE,
+    created_at timestamptz,
+    PRIMARY KEY (user_id, role_id)
+);
+
+CREATE INDEX IF NOT EXISTS idx_user_roles_role_id ON user_roles (role_id);
@@ -0,0 +1,6 @@
+-- deactivation and forced password reset of users by admin:
+-- deactivated_at decides whether user can sign in, is_active is kept in sync on save
+ALTER TABLE users ADD COLUMN IF NOT EXISTS deactivated_at timestamptz;
+ALTER TABLE users ADD COLUMN IF NOT EXISTS password_reset_required boolean NOT NULL DEFAULT false;
+
+UPDATE users SET is_active = (deactivated_at IS NULL);